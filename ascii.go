@@ -0,0 +1,43 @@
+package corpus
+
+import (
+    "math"
+    "strings"
+)
+
+// RenderASCII draws the world as text of rows lines of cols characters,
+// each ending in a newline, marking with 'o' the cells holding the center
+// of a corpus and with '.' the empty ones. The grid spans the same area as
+// WriteSVG, and corpi outside of it are left out.
+func (w *World) RenderASCII(cols, rows int) string {
+    if cols <= 0 || rows <= 0 {
+        return ""
+    }
+    grid := make([][]byte, rows)
+    for y := range grid {
+        grid[y] = []byte(strings.Repeat(".", cols))
+    }
+    min, max := w.view()
+    size := max.Sub(min)
+    for _, c := range w.Corpi {
+        p := c.Pos.Sub(min)
+        x := int(math.Floor(p.X / size.X * float64(cols)))
+        y := int(math.Floor(p.Y / size.Y * float64(rows)))
+        // The far edges belong to the last cells.
+        if x == cols && p.X == size.X {
+            x--
+        }
+        if y == rows && p.Y == size.Y {
+            y--
+        }
+        if x >= 0 && x < cols && y >= 0 && y < rows {
+            grid[y][x] = 'o'
+        }
+    }
+    var b strings.Builder
+    for _, line := range grid {
+        b.Write(line)
+        b.WriteByte('\n')
+    }
+    return b.String()
+}
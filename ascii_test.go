@@ -0,0 +1,38 @@
+package corpus
+
+import (
+	"testing"
+)
+
+func TestWorld_RenderASCII(t *testing.T) {
+	w := NewWorld(100, 60)
+	w.Add(Corpus{Pos: Vector{50, 30}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{100, 0}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{150, 30}, Mass: 1, Radius: 1})
+
+	want := "....o\n" +
+		".....\n" +
+		"..o..\n" +
+		".....\n" +
+		".....\n"
+
+	if got := w.RenderASCII(5, 5); got != want {
+		t.Error("WRONG !!\n" + got)
+	}
+
+	if w.RenderASCII(0, 5) != "" {
+		t.Error("WRONG !!")
+	}
+
+	// Corpi lined up without extent along an axis do not divide by zero.
+	w = NewWorld(0, 0)
+	w.Add(Corpus{Pos: Vector{5, 0}})
+	w.Add(Corpus{Pos: Vector{5, 10}})
+	want = "o..\n" +
+		"...\n" +
+		"o..\n"
+
+	if got := w.RenderASCII(3, 3); got != want {
+		t.Error("WRONG !!\n" + got)
+	}
+}
@@ -0,0 +1,158 @@
+package corpus
+
+import "math"
+
+// bhNode is a node of a Barnes-Hut tree covering the square from min with
+// the given side, holding the total mass and center of mass of its corpi.
+// A leaf holds the indices of its corpi, an inner node its four quadrants.
+type bhNode struct {
+    min      Vector
+    side     float64
+    mass     float64
+    center   Vector
+    bodies   []int
+    children []bhNode
+}
+
+// bhMaxDepth bounds the depth of a Barnes-Hut tree, so that coincident
+// corpi end up sharing a leaf instead of splitting forever.
+const bhMaxDepth = 48
+
+// GravitateBarnesHut calculates and accumulates the softened gravitational
+// force of the world, like GravitateAll with w.G and w.Softening, using
+// the Barnes-Hut approximation: a group of corpi seen under an angle
+// below theta (its size over its distance) pulls as a single body at its
+// center of mass. That takes O(n log n) instead of O(n^2); theta 0 gives
+// the exact forces, and larger values trade accuracy for speed.
+func (w *World) GravitateBarnesHut(theta float64) {
+    w.sync()
+    root, ok := buildBarnesHut(w.Corpi)
+    if !ok {
+        return
+    }
+    for idx := range w.Corpi {
+        if !w.Corpi[idx].Immaterial {
+            root.gravitate(w.Corpi, idx, w.G, w.Softening, theta)
+        }
+    }
+}
+
+// buildBarnesHut returns the root of a tree holding the material corpi,
+// and false if there are none.
+func buildBarnesHut(corpi []Corpus) (*bhNode, bool) {
+    var min, max Vector
+    first := true
+    for idx := range corpi {
+        if corpi[idx].Immaterial {
+            continue
+        }
+        if first {
+            min, max = corpi[idx].Pos, corpi[idx].Pos
+            first = false
+        }
+        min, max = min.Min(corpi[idx].Pos), max.Max(corpi[idx].Pos)
+    }
+    if first {
+        return nil, false
+    }
+
+    size := max.Sub(min)
+    root := &bhNode{min: min, side: size.X}
+    if size.Y > root.side {
+        root.side = size.Y
+    }
+    for idx := range corpi {
+        if !corpi[idx].Immaterial {
+            root.insert(corpi, idx, 0)
+        }
+    }
+    root.summarize(corpi)
+    return root, true
+}
+
+// insert adds the corpus at idx to the leaf covering its position,
+// splitting leaves that would hold more than one corpus.
+func (n *bhNode) insert(corpi []Corpus, idx, depth int) {
+    if n.children != nil {
+        n.child(corpi[idx].Pos).insert(corpi, idx, depth+1)
+        return
+    }
+    n.bodies = append(n.bodies, idx)
+    if len(n.bodies) > 1 && depth < bhMaxDepth {
+        half := n.side / 2
+        n.children = []bhNode{
+            {min: n.min, side: half},
+            {min: n.min.Add(Vector{half, 0}), side: half},
+            {min: n.min.Add(Vector{0, half}), side: half},
+            {min: n.min.Add(Vector{half, half}), side: half},
+        }
+        for _, body := range n.bodies {
+            n.child(corpi[body].Pos).insert(corpi, body, depth+1)
+        }
+        n.bodies = nil
+    }
+}
+
+// child returns the quadrant of the node covering the position.
+func (n *bhNode) child(pos Vector) *bhNode {
+    mid := n.min.Add(Vector{n.side / 2, n.side / 2})
+    idx := 0
+    if pos.X >= mid.X {
+        idx++
+    }
+    if pos.Y >= mid.Y {
+        idx += 2
+    }
+    return &n.children[idx]
+}
+
+// summarize computes the total mass and center of mass of every node.
+func (n *bhNode) summarize(corpi []Corpus) {
+    var moment Vector
+    for _, body := range n.bodies {
+        n.mass += corpi[body].Mass
+        moment = moment.Add(corpi[body].Pos.Mult(corpi[body].Mass))
+    }
+    for idx := range n.children {
+        child := &n.children[idx]
+        child.summarize(corpi)
+        n.mass += child.mass
+        moment = moment.Add(child.center.Mult(child.mass))
+    }
+    if n.mass != 0 {
+        n.center = moment.Div(n.mass)
+    }
+}
+
+// contains returns true if the position lies within the square of the node.
+func (n *bhNode) contains(pos Vector) bool {
+    return pos.X >= n.min.X && pos.Y >= n.min.Y && pos.X <= n.min.X+n.side && pos.Y <= n.min.Y+n.side
+}
+
+// gravitate applies to the corpus at idx the pull of the corpi in the node.
+func (n *bhNode) gravitate(corpi []Corpus, idx int, G, eps, theta float64) {
+    c := &corpi[idx]
+    if n.mass == 0 {
+        return
+    }
+    if n.children == nil {
+        for _, body := range n.bodies {
+            // Same force, and cutoff, as GravitateSoftened.
+            if force, ok := c.gravity(&corpi[body], G, eps); ok {
+                c.AddForce(force)
+            }
+        }
+        return
+    }
+
+    // s/d < theta, never for a node around the corpus itself.
+    dist := c.Pos.Dist(n.center)
+    if !n.contains(c.Pos) && n.side < theta*dist {
+        distSq := dist*dist + eps*eps
+        c.AddForce(n.center.Sub(c.Pos).Mult(G * c.Mass * n.mass / distSq).Div(math.Sqrt(distSq)))
+        return
+    }
+    for child := range n.children {
+        n.children[child].gravitate(corpi, idx, G, eps, theta)
+    }
+}
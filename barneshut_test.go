@@ -0,0 +1,95 @@
+package corpus
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// gravityWorld returns a world of n corpi of random mass scattered over a
+// size by size square, with gravity and softening set.
+func gravityWorld(r *rand.Rand, n int, size float64) *World {
+	w := NewWorld(0, 0)
+	w.G, w.Softening = 1, 0.5
+	for idx := 0; idx < n; idx++ {
+		w.Add(Corpus{
+			Pos:    Vector{r.Float64() * size, r.Float64() * size},
+			Mass:   0.5 + r.Float64(),
+			Radius: 0.1,
+		})
+	}
+	return w
+}
+
+func TestWorld_GravitateBarnesHut(t *testing.T) {
+	w := gravityWorld(rand.New(rand.NewSource(1)), 500, 100)
+	w.Corpi[3].Immaterial = true
+	w.Corpi[4].Static = true
+
+	brute := make([]Corpus, len(w.Corpi))
+	copy(brute, w.Corpi)
+	GravitateAll(brute, w.G, w.Softening)
+
+	// Forces nearly cancel for some corpi, so errors are measured
+	// relative to the mean brute-force force.
+	mean := 0.0
+	for idx := range brute {
+		mean += brute[idx].Force.Mag() / float64(len(brute))
+	}
+
+	// relErr returns the worst relative error of the forces.
+	relErr := func(theta float64) float64 {
+		for idx := range w.Corpi {
+			w.Corpi[idx].Force = Vector{}
+		}
+		w.GravitateBarnesHut(theta)
+		worst := 0.0
+		for idx := range w.Corpi {
+			worst = math.Max(worst, w.Corpi[idx].Force.Dist(brute[idx].Force)/mean)
+		}
+		return worst
+	}
+
+	if relErr(0) > 1e-9 {
+		t.Error("WRONG !!")
+	}
+
+	// The error shrinks along with theta.
+	coarse, fine := relErr(1), relErr(0.3)
+
+	if fine > 0.02 || fine >= coarse {
+		t.Error("WRONG !!")
+	}
+
+	if !w.Corpi[3].Force.IsZero() {
+		t.Error("WRONG !!")
+	}
+
+	// A static corpus is pulled but never accelerates.
+	w.Corpi[4].ApplyNetForce()
+
+	if !w.Corpi[4].Acc.IsZero() || !w.Corpi[4].Force.IsZero() {
+		t.Error("WRONG !!")
+	}
+
+	// An empty world is left alone.
+	NewWorld(0, 0).GravitateBarnesHut(0.5)
+}
+
+func BenchmarkWorld_GravitateBarnesHut(b *testing.B) {
+	w := gravityWorld(rand.New(rand.NewSource(1)), 10000, 1000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		w.GravitateBarnesHut(0.5)
+	}
+}
+
+func BenchmarkGravitateAll(b *testing.B) {
+	w := gravityWorld(rand.New(rand.NewSource(1)), 10000, 1000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		GravitateAll(w.Corpi, w.G, w.Softening)
+	}
+}
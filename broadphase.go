@@ -0,0 +1,165 @@
+package corpus
+
+import (
+    "math"
+    "sort"
+)
+
+// Pair is a pair of indices into a slice of corpi, with A < B.
+type Pair struct {
+    A, B int
+}
+
+// Broadphase finds the pairs of corpi that may be colliding, so that only
+// those need the exact and costly collision test.
+// Pairs must return every pair of material corpi whose bounding boxes
+// overlap, sorted by A and then B, which is the order ResolveCollisions
+// visits them in.
+type Broadphase interface {
+    Pairs(corpi []Corpus) []Pair
+}
+
+// Querier is a Broadphase that can also index corpi for spatial queries.
+type Querier interface {
+    Broadphase
+    Index(corpi []Corpus) Index
+}
+
+// Index is a spatial index of a slice of corpi as they were when it was
+// built, so that the corpi in an area are found without testing them all.
+// Query must return the indices of the corpi whose bounding boxes overlap
+// the box from min to max, in increasing order.
+type Index interface {
+    Query(min, max Vector) []int
+}
+
+// SpatialHash is a Broadphase that buckets corpi into a uniform grid of
+// square cells, so only corpi sharing a cell are paired. It works best
+// when CellSize is about the diameter of a typical corpus.
+type SpatialHash struct {
+    CellSize float64
+}
+
+// cell is the coordinates of a grid cell of a SpatialHash.
+type cell struct {
+    X, Y int
+}
+
+// cellOf returns the cell containing the point p.
+func (h *SpatialHash) cellOf(p Vector) cell {
+    return cell{int(math.Floor(p.X / h.CellSize)), int(math.Floor(p.Y / h.CellSize))}
+}
+
+// grid buckets the corpi into the cells their bounding boxes cover,
+// skipping immaterial corpi unless all is true.
+func (h *SpatialHash) grid(corpi []Corpus, all bool) map[cell][]int {
+    grid := make(map[cell][]int)
+    for idx := range corpi {
+        if corpi[idx].Immaterial && !all {
+            continue
+        }
+        min, max := corpi[idx].Bounds()
+        lo, hi := h.cellOf(min), h.cellOf(max)
+        for x := lo.X; x <= hi.X; x++ {
+            for y := lo.Y; y <= hi.Y; y++ {
+                grid[cell{x, y}] = append(grid[cell{x, y}], idx)
+            }
+        }
+    }
+    return grid
+}
+
+// Pairs returns the pairs of material corpi whose bounding boxes overlap.
+func (h *SpatialHash) Pairs(corpi []Corpus) []Pair {
+    grid := h.grid(corpi, false)
+
+    var pairs []Pair
+    for k, bucket := range grid {
+        for i, a := range bucket {
+            minA, maxA := corpi[a].Bounds()
+            for _, b := range bucket[i+1:] {
+                minB, maxB := corpi[b].Bounds()
+                if !boxesOverlap(minA, maxA, minB, maxB) {
+                    continue
+                }
+                // Corpi sharing several cells are paired only in the cell
+                // holding the corner of the overlap of their boxes.
+                if h.cellOf(minA.Max(minB)) == k {
+                    pairs = append(pairs, Pair{a, b})
+                }
+            }
+        }
+    }
+    sortPairs(pairs)
+    return pairs
+}
+
+// Index returns an index of all corpi, immaterial included.
+func (h *SpatialHash) Index(corpi []Corpus) Index {
+    min, max := bounds(corpi)
+    return &hashIndex{cellSize: h.CellSize, grid: h.grid(corpi, true), min: min, max: max}
+}
+
+// hashIndex is the Index of a SpatialHash.
+type hashIndex struct {
+    cellSize float64
+    grid     map[cell][]int
+    min, max []Vector // bounding boxes of the corpi
+}
+
+// Query returns the indices of the corpi whose bounding boxes overlap
+// the box from min to max, in increasing order.
+func (x *hashIndex) Query(min, max Vector) []int {
+    h := SpatialHash{CellSize: x.cellSize}
+    lo, hi := h.cellOf(min), h.cellOf(max)
+    var found []int
+    // visit adds the corpi of a cell overlapping the box. Corpi covering
+    // several cells are only added in the cell holding the corner of the
+    // overlap of their box with the queried one.
+    visit := func(k cell, bucket []int) {
+        for _, idx := range bucket {
+            if boxesOverlap(min, max, x.min[idx], x.max[idx]) && h.cellOf(min.Max(x.min[idx])) == k {
+                found = append(found, idx)
+            }
+        }
+    }
+    // Large boxes visit the occupied cells rather than every covered one.
+    if cells := (float64(hi.X-lo.X) + 1) * (float64(hi.Y-lo.Y) + 1); cells > float64(len(x.grid)) {
+        for k, bucket := range x.grid {
+            visit(k, bucket)
+        }
+    } else {
+        for cx := lo.X; cx <= hi.X; cx++ {
+            for cy := lo.Y; cy <= hi.Y; cy++ {
+                visit(cell{cx, cy}, x.grid[cell{cx, cy}])
+            }
+        }
+    }
+    sort.Ints(found)
+    return found
+}
+
+// bounds returns the bounding boxes of the corpi.
+func bounds(corpi []Corpus) (min, max []Vector) {
+    min, max = make([]Vector, len(corpi)), make([]Vector, len(corpi))
+    for idx := range corpi {
+        min[idx], max[idx] = corpi[idx].Bounds()
+    }
+    return min, max
+}
+
+// boxesOverlap reports whether the two axis-aligned boxes overlap,
+// touching included.
+func boxesOverlap(minA, maxA, minB, maxB Vector) bool {
+    return minA.X <= maxB.X && minB.X <= maxA.X && minA.Y <= maxB.Y && minB.Y <= maxA.Y
+}
+
+// sortPairs sorts the pairs by A and then B.
+func sortPairs(pairs []Pair) {
+    sort.Slice(pairs, func(i, j int) bool {
+        if pairs[i].A != pairs[j].A {
+            return pairs[i].A < pairs[j].A
+        }
+        return pairs[i].B < pairs[j].B
+    })
+}
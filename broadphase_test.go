@@ -0,0 +1,130 @@
+package corpus
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randomCorpi returns n corpi of random radius up to maxRad at rest,
+// scattered uniformly over a size by size square.
+func randomCorpi(r *rand.Rand, n int, size, maxRad float64) []Corpus {
+	corpi := make([]Corpus, n)
+	for idx := range corpi {
+		corpi[idx] = Corpus{
+			Pos:    Vector{r.Float64() * size, r.Float64() * size},
+			Vel:    RandUnit(r),
+			Mass:   1,
+			Radius: 0.1 + r.Float64()*maxRad,
+		}
+	}
+	return corpi
+}
+
+// overlapping returns the pairs of material corpi that intersect,
+// by testing every pair.
+func overlapping(corpi []Corpus) map[Pair]bool {
+	res := make(map[Pair]bool)
+	for i := range corpi {
+		for j := i + 1; j < len(corpi); j++ {
+			if !corpi[i].Immaterial && !corpi[j].Immaterial && corpi[i].IsInter(&corpi[j]) {
+				res[Pair{i, j}] = true
+			}
+		}
+	}
+	return res
+}
+
+// checkPairs checks that the pairs are sorted, unique and include every
+// overlapping pair of corpi.
+func checkPairs(t *testing.T, corpi []Corpus, pairs []Pair) {
+	for idx := 1; idx < len(pairs); idx++ {
+		a, b := pairs[idx-1], pairs[idx]
+		if a.A > b.A || (a.A == b.A && a.B >= b.B) {
+			t.Error("WRONG !! unsorted")
+		}
+	}
+
+	found := make(map[Pair]bool)
+	for _, p := range pairs {
+		found[p] = true
+	}
+
+	for p := range overlapping(corpi) {
+		if !found[p] {
+			t.Error("WRONG !! missing", p)
+		}
+	}
+}
+
+func TestSpatialHash_Pairs(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	corpi := randomCorpi(r, 500, 100, 3)
+	corpi[3].Immaterial = true
+	pairs := (&SpatialHash{CellSize: 4}).Pairs(corpi)
+
+	checkPairs(t, corpi, pairs)
+
+	for _, p := range pairs {
+		if p.A == 3 || p.B == 3 {
+			t.Error("WRONG !!")
+		}
+	}
+
+	// Cells much smaller than the corpi still find every pair once.
+	checkPairs(t, corpi, (&SpatialHash{CellSize: 0.5}).Pairs(corpi))
+}
+
+func TestWorld_SetBroadphase(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	brute := NewWorld(200, 200)
+	// Start from a sparse jittered lattice, so collisions are between pairs
+	// and resolving one never pushes a corpus into another.
+	for x := 10.0; x < 200; x += 20 {
+		for y := 10.0; y < 200; y += 20 {
+			brute.Add(Corpus{Pos: Vector{x, y}.Add(RandUnit(r)), Vel: RandUnit(r), Mass: 1, Radius: 1 + r.Float64()*3})
+		}
+	}
+	grid := NewWorld(200, 200)
+	grid.Corpi = append([]Corpus(nil), brute.Corpi...)
+	grid.SetBroadphase(4)
+
+	if _, ok := grid.Broadphase.(*SpatialHash); !ok {
+		t.Error("WRONG !!")
+	}
+
+	for i := 0; i < 50; i++ {
+		brute.Step(1)
+		grid.Step(1)
+		for idx := range brute.Corpi {
+			if brute.Corpi[idx] != grid.Corpi[idx] {
+				t.Fatal("WRONG !!")
+			}
+		}
+	}
+
+	grid.SetBroadphase(0)
+
+	if grid.Broadphase != nil {
+		t.Error("WRONG !!")
+	}
+}
+
+func benchmarkWorldStep(b *testing.B, n int, broadphase Broadphase) {
+	r := rand.New(rand.NewSource(1))
+	w := NewWorld(1000, 1000)
+	w.Corpi = randomCorpi(r, n, 1000, 2)
+	w.Broadphase = broadphase
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		w.Step(1)
+	}
+}
+
+func BenchmarkWorld_Step_BruteForce(b *testing.B) {
+	benchmarkWorldStep(b, 5000, nil)
+}
+
+func BenchmarkWorld_Step_SpatialHash(b *testing.B) {
+	benchmarkWorldStep(b, 5000, &SpatialHash{CellSize: 4})
+}
@@ -1,32 +1,67 @@
 /*
 Package corpus implements a 2D physical object "Corpus"
-and a 2D vector "Vector" with related mathematical operations.
+and a 2D vector "Vector" with related mathematical operations,
+and a "World" that steps a system of corpi through time.
 */
 package corpus
 
 import (
     "math"
     "fmt"
+    "math/rand"
+    "image/color"
 )
 
 // Corpus is a 2D physical disk with vectors for position, velocity
-// acceleration, scalars for mass and radius and a boolean Immaterial
-// for things simply to be drawn.
+// acceleration, scalars for mass and radius, a boolean Immaterial
+// for things simply to be drawn and a boolean Static for things
+// of infinite mass, like walls, that collide but never move.
 type Corpus struct {
     Pos, Vel, Acc        Vector
     Mass, Charge, Radius float64
-    Immaterial           bool
+    Immaterial, Static   bool
     Tag                  string
-}
+    // Color is the color renderers draw the corpus with, ignored by the
+    // physics. The constructors make it opaque white, which renderers also
+    // use for the zero Color.
+    Color color.RGBA
+    // Force is the net force accumulated by AddForce, which the Update
+    // methods apply before integrating, see ApplyNetForce.
+    Force Vector
+    // Layer is the set of collision layers the corpus is on, and Mask the
+    // set of layers it collides with, one bit each. A zero Layer is layer 1
+    // and a zero Mask collides with every layer.
+    Layer, Mask uint32
+    // UserData is left for the user to attach anything to the corpus,
+    // such as the game entity it is the body of. It is never touched,
+    // copied along with the corpus and not encoded.
+    UserData interface{}
+    // Life is the time left before the corpus dies, counted down by the
+    // Update methods once set with SetLife.
+    Life float64
+
+    prevAcc Vector // acceleration of the last UpdateVerlet step
+    verlet  bool   // whether prevAcc is valid
+    idle    int    // consecutive World steps spent below the sleep thresholds
+    asleep  bool   // whether World skips the corpus until woken
 
-// Vector is a 2D vector with x and y components of type float64.
-type Vector struct {
-    X, Y float64
+    maxSpeed float64 // terminal speed, 0 for none
+    mortal   bool    // whether Life counts down
 }
 
+// Vector is a 2D vector with x and y components of type float64, the Vec
+// the package is written in.
+type Vector = Vec[float64]
+
+// white is the default Color of a corpus.
+var white = color.RGBA{255, 255, 255, 255}
+
+// Epsilon is the suggested tolerance for comparing vectors with Equals.
+const Epsilon = 1e-9
+
 // MakeCorpus initialises and returns a Corpus with
-// given Pos, Vel and Rad all in float64 forms.
-// By default, Acc is 0,0 and Immaterial is false.
+// given Pos, Vel, Mass, Charge and Radius all in float64 forms,
+// in that order. By default, Acc is 0,0 and Immaterial is false.
 func MakeCorpus(posX, posY, velX, velY, mass, charge, rad float64) Corpus {
     c := Corpus{}
     c.Pos = Vector{X: posX, Y: posY}
@@ -36,116 +71,721 @@ func MakeCorpus(posX, posY, velX, velY, mass, charge, rad float64) Corpus {
     c.Radius = rad
     c.Acc = Vector{0, 0}
     c.Immaterial = false
+    c.Color = white
     c.Tag = fmt.Sprint(c.Pos, c.Vel, c.Mass, c.Charge, c.Acc)
 
     return c
 }
 
+// CorpusOption sets a property of a Corpus built by NewCorpus.
+type CorpusOption func(*Corpus)
+
+// NewCorpus initialises and returns a Corpus configured by the given options.
+// Unset properties default to a unit Mass and Radius at rest at the origin,
+// with no Charge, Immaterial false and a white Color.
+func NewCorpus(opts ...CorpusOption) Corpus {
+    c := Corpus{Mass: 1, Radius: 1, Color: white}
+    for _, opt := range opts {
+        opt(&c)
+    }
+    c.Tag = fmt.Sprint(c.Pos, c.Vel, c.Mass, c.Charge, c.Acc)
+
+    return c
+}
+
+// WithPos sets the position of the corpus.
+func WithPos(pos Vector) CorpusOption {
+    return func(c *Corpus) { c.Pos = pos }
+}
+
+// WithVel sets the velocity of the corpus.
+func WithVel(vel Vector) CorpusOption {
+    return func(c *Corpus) { c.Vel = vel }
+}
+
+// WithMass sets the mass of the corpus.
+func WithMass(mass float64) CorpusOption {
+    return func(c *Corpus) { c.Mass = mass }
+}
+
+// WithCharge sets the charge of the corpus.
+func WithCharge(charge float64) CorpusOption {
+    return func(c *Corpus) { c.Charge = charge }
+}
+
+// WithRadius sets the radius of the corpus.
+func WithRadius(rad float64) CorpusOption {
+    return func(c *Corpus) { c.Radius = rad }
+}
+
+// WithColor sets the color of the corpus.
+func WithColor(col color.RGBA) CorpusOption {
+    return func(c *Corpus) { c.Color = col }
+}
+
+// WithImmaterial sets whether the corpus is immaterial.
+func WithImmaterial(immaterial bool) CorpusOption {
+    return func(c *Corpus) { c.Immaterial = immaterial }
+}
+
+// WithLayer sets the collision layer and mask of the corpus.
+func WithLayer(layer, mask uint32) CorpusOption {
+    return func(c *Corpus) { c.Layer, c.Mask = layer, mask }
+}
+
+// WithMaxSpeed sets the maximum speed of the corpus, see SetMaxSpeed.
+func WithMaxSpeed(v float64) CorpusOption {
+    return func(c *Corpus) { c.SetMaxSpeed(v) }
+}
+
+// WithStatic sets whether the corpus is static.
+func WithStatic(static bool) CorpusOption {
+    return func(c *Corpus) { c.Static = static }
+}
+
+// IsAsleep returns true if the corpus was put to sleep by a World after
+// coming to rest, so that it is skipped until it is woken.
+func (c Corpus) IsAsleep() bool {
+    return c.asleep
+}
+
+// IsDead returns true if the corpus has a life set with SetLife
+// and it has run out.
+func (c Corpus) IsDead() bool {
+    return c.mortal && c.Life <= 0
+}
+
 // IsInter checks if two corpi intersect each other.
 func (c Corpus) IsInter(cp *Corpus) bool {
     return c.Pos.Dist(cp.Pos) <= c.Radius+cp.Radius
 }
 
+// invMass returns the inverse mass of the corpus,
+// 0 for a static corpus or one of mass at most 0.
+func (c Corpus) invMass() float64 {
+    if c.Static || c.Mass <= 0 {
+        return 0
+    }
+    return 1 / c.Mass
+}
+
+// drawColor returns the color to draw the corpus with, white for the zero
+// Color.
+func (c Corpus) drawColor() color.RGBA {
+    if c.Color == (color.RGBA{}) {
+        return white
+    }
+    return c.Color
+}
+
+// moveVel returns the velocity the corpus moves with in Update,
+// 0 for a static or immaterial corpus.
+func (c Corpus) moveVel() Vector {
+    if c.Static || c.Immaterial {
+        return Vector{0, 0}
+    }
+    return c.Vel
+}
+
+// ApplyDrag subjects the corpus to a linear drag force opposing its
+// velocity, "F = -k*v".
+func (c *Corpus) ApplyDrag(coeff float64) {
+    c.ApplyForce(c.Vel.Mult(-coeff))
+}
+
+// ApplyDragQuadratic subjects the corpus to a quadratic drag force opposing
+// its velocity, "F = -k*|v|*v", as for air resistance at higher speeds.
+func (c *Corpus) ApplyDragQuadratic(coeff float64) {
+    c.ApplyForce(c.Vel.Mult(-coeff * c.Vel.Mag()))
+}
+
+// AddForce accumulates the given force into the net force of the corpus,
+// leaving its acceleration alone until the net force is applied.
+func (c *Corpus) AddForce(f Vector) {
+    c.Force.AddP(f)
+}
+
 // ApplyForce subjects the corpus to the given force by mutating its acceleration.
 // By Newton's 2nd law "F = m*a".
+// A static corpus or one of mass at most 0 is immovable and so unaffected.
 func (c *Corpus) ApplyForce(f Vector) {
+    if c.Static || c.Mass <= 0 {
+        return
+    }
     c.Acc.AddP(f.Div(c.Mass)) // a = F/m
 }
 
+// ApplyNetForce applies the net force accumulated by AddForce with
+// ApplyForce, and clears it.
+func (c *Corpus) ApplyNetForce() {
+    if c.Force != (Vector{0, 0}) {
+        c.ApplyForce(c.Force)
+        c.Force = Vector{0, 0}
+    }
+}
+
+// ApplyGravity subjects the corpus to a uniform gravitational field g
+// by applying the force "F = m*g", so it accelerates by g whatever its mass.
+func (c *Corpus) ApplyGravity(g Vector) {
+    c.ApplyForce(g.Mult(c.Mass))
+}
+
+// ApplyImpulse subjects the corpus to the given instantaneous impulse by
+// mutating its velocity directly, bypassing acceleration.
+// By the impulse-momentum theorem "J = m*dv".
+// A static corpus or one of mass at most 0 is unaffected.
+func (c *Corpus) ApplyImpulse(j Vector) {
+    if c.Static || c.Mass <= 0 {
+        return
+    }
+    c.Vel.AddP(j.Div(c.Mass)) // dv = J/m
+}
+
+// ApplyMagnetic subjects the corpus to the magnetic part of the Lorentz
+// force in a uniform field of strength B along the out-of-plane z axis.
+// F = q*v x B = q*B*{vy, -vx}
+// A positive charge in a positive field circles clockwise.
+func (c *Corpus) ApplyMagnetic(B float64) {
+    c.ApplyForce(c.Vel.PerpCW().Mult(c.Charge * B))
+}
+
+// ApplySpring applies a damped spring force between the given corpus and cp
+// along the axis connecting them, equal and opposite on both.
+// Using Hooke's law with damping on the relative velocity along the axis:
+// F = -k*(r-rest) - d*<v1-v2, n>
+func (c *Corpus) ApplySpring(cp *Corpus, restLength, k, damping float64) {
+    n := c.Pos.DirectionTo(cp.Pos)
+    stretch := c.Pos.Dist(cp.Pos) - restLength
+    relVel := cp.Vel.Sub(c.Vel).Dot(n)
+    force := n.Mult(k*stretch + damping*relVel)
+    c.ApplyForce(force)
+    cp.ApplyForce(force.Neg())
+}
+
 // Bounce bounces the corpus off windows boundaries given by width and height.
 // Returns the number of collisions with the boundaries, 0 if none.
 // Also prevents intersections by directly mutating position.
+// A corpus too large to fit along an axis is centered and stopped
+// along that axis instead. A static corpus never bounces.
 func (c *Corpus) Bounce(width, height float64) int {
+    return c.BounceRestitution(width, height, 1)
+}
+
+// BounceRestitution is like Bounce, but multiplies the reflected velocity
+// component by the coefficient of restitution e, losing energy on every hit.
+func (c *Corpus) BounceRestitution(width, height, e float64) int {
+    if c.Static {
+        return 0
+    }
     posX := c.Pos.X
     posY := c.Pos.Y
     rad := c.Radius
     collisions := 0
 
-    if posX > width-rad {
-        c.Pos.X = width - rad
-        c.Vel.X = -c.Vel.X
-        collisions += 1
+    // A corpus wider than the window touches both sides at once,
+    // so it is held at the center instead of bouncing back and forth.
+    if 2*rad >= width {
+        c.Pos.X = width / 2
+        c.Vel.X = 0
+    } else {
+        if posX > width-rad {
+            c.Pos.X = width - rad
+            c.Vel.X = -e * c.Vel.X
+            collisions += 1
+        }
+        if posX < rad {
+            c.Pos.X = rad
+            c.Vel.X = -e * c.Vel.X
+            collisions += 1
+        }
     }
-    if posX < rad {
-        c.Pos.X = rad
-        c.Vel.X = -c.Vel.X
-        collisions += 1
+    if 2*rad >= height {
+        c.Pos.Y = height / 2
+        c.Vel.Y = 0
+    } else {
+        if posY > height-rad {
+            c.Pos.Y = height - rad
+            c.Vel.Y = -e * c.Vel.Y
+            collisions += 1
+        }
+        if posY < rad {
+            c.Pos.Y = rad
+            c.Vel.Y = -e * c.Vel.Y
+            collisions += 1
+        }
     }
-    if posY > height-rad {
-        c.Pos.Y = height - rad
-        c.Vel.Y = -c.Vel.Y
-        collisions += 1
+    return collisions
+}
+
+// BounceSwept bounces the corpus off windows boundaries given by width and
+// height anywhere along its path over the next timestep dt, so a corpus fast
+// enough to cross the whole window in one step still bounces correctly.
+// It is meant to be called right before Update: it moves the corpus back
+// along its outgoing velocity so that Update carries it to where it would
+// have been after bouncing mid-step. Acceleration is not considered.
+// e is the coefficient of restitution as in BounceRestitution.
+// Returns the number of collisions with the boundaries, 0 if none.
+func (c *Corpus) BounceSwept(width, height, e, dt float64) int {
+    if c.Static {
+        return 0
     }
-    if posY < rad {
-        c.Pos.Y = rad
-        c.Vel.Y = -c.Vel.Y
-        collisions += 1
+    var hitsX, hitsY int
+    c.Pos.X, c.Vel.X, hitsX = sweepAxis(c.Pos.X, c.Vel.X, c.Radius, width, e, dt)
+    c.Pos.Y, c.Vel.Y, hitsY = sweepAxis(c.Pos.Y, c.Vel.Y, c.Radius, height, e, dt)
+    return hitsX + hitsY
+}
+
+// sweepAxis follows a disk of radius rad moving along one axis of a window
+// of the given size for dt, bouncing off both walls with restitution e.
+// Returns the position from which the final velocity reaches the end
+// position in dt, the final velocity and the number of bounces.
+func sweepAxis(pos, vel, rad, size, e, dt float64) (float64, float64, int) {
+    lo, hi := rad, size-rad
+    if lo >= hi {
+        return size / 2, 0, 0
     }
-    return collisions
+    hits := 0
+    if pos > hi || pos < lo {
+        if (pos > hi && vel > 0) || (pos < lo && vel < 0) {
+            vel = -e * vel
+            hits += 1
+        }
+        pos = Clamp(pos, lo, hi)
+    }
+    t := dt
+    for t > 0 && vel != 0 && hits < 64 {
+        next := pos + vel*t
+        wall := hi
+        if next < lo {
+            wall = lo
+        } else if next <= hi {
+            pos = next
+            t = 0
+            break
+        }
+        t -= (wall - pos) / vel
+        pos = wall
+        vel = -e * vel
+        hits += 1
+    }
+    pos += vel * t
+    return pos - vel*dt, vel, hits
+}
+
+// Bounds returns the corners of the axis-aligned bounding box of the corpus.
+func (c Corpus) Bounds() (min, max Vector) {
+    r := Vector{c.Radius, c.Radius}
+    return c.Pos.Sub(r), c.Pos.Add(r)
+}
+
+// CanCollide returns true if the masks of both corpi include the layer of
+// the other, so that they may collide.
+func (c Corpus) CanCollide(cp *Corpus) bool {
+    return c.mask()&cp.layer() != 0 && cp.mask()&c.layer() != 0
+}
+
+// layer returns the collision layer of the corpus, 1 if unset.
+func (c Corpus) layer() uint32 {
+    if c.Layer == 0 {
+        return 1
+    }
+    return c.Layer
+}
+
+// mask returns the collision mask of the corpus, every layer if unset.
+func (c Corpus) mask() uint32 {
+    if c.Mask == 0 {
+        return math.MaxUint32
+    }
+    return c.Mask
+}
+
+// CenterOfMass returns the mass-weighted average position of the material
+// corpi with a positive mass:
+// R = sum(m*r) / sum(m)
+// It is the zero vector if there are none.
+func CenterOfMass(corpi []Corpus) Vector {
+    sum, mass := Vector{0, 0}, 0.0
+    for idx := range corpi {
+        c := &corpi[idx]
+        if c.Immaterial || c.Mass <= 0 {
+            continue
+        }
+        sum.AddP(c.Pos.Mult(c.Mass))
+        mass += c.Mass
+    }
+    if mass == 0 {
+        return Vector{0, 0}
+    }
+    return sum.Div(mass)
+}
+
+// Clone returns an independent copy of the corpus.
+// A Corpus holds only values but for UserData, which is shared, so a plain
+// assignment copies it just as well; Clone is the copy that stays deep
+// should other reference fields be added.
+func (c Corpus) Clone() Corpus {
+    return c
+}
+
+// ClosestSurfacePoint returns the point on the boundary of the corpus
+// nearest to p. If p is at the center every boundary point is equally near,
+// and the one in the direction of the positive X axis is returned.
+func (c Corpus) ClosestSurfacePoint(p Vector) Vector {
+    dir := c.Pos.DirectionTo(p)
+    if dir.IsZero() {
+        dir = Vector{1, 0}
+    }
+    return c.Pos.Add(dir.Mult(c.Radius))
 }
 
 // Collide collides the given corpus with the given corpi in the slice.
 // Mutates velocities to preserve momentum and kinetic energy.
 // Also prevents intersections by directly mutating positions.
-// Solving the equation for a 2D elastic collision yields:
-// v1' = v1 - (2*m2/(m1+m2)) * (<v1-v2, x1-x2>)/(||x1-x2||^2) * (x1-x2)
-// v2' = v2 - (2*m1/(m1+m2)) * (<v2-v1, x2-x1>)/(||x2-x1||^2) * (x2-x1)
+// It is CollideWith for every corpus in the slice with a perfectly
+// elastic coefficient of restitution of 1. The corpus itself is skipped
+// if it is in the slice. Calling Collide for every corpus of a slice
+// resolves each pair twice; use ResolveCollisions for that instead.
 func (c *Corpus) Collide(corpi []Corpus) {
     for idx := range corpi {
-        cp := &corpi[idx]
-        if !c.Immaterial && !cp.Immaterial {
-            dist := c.Pos.Dist(cp.Pos)
-            // There is a collision.
-            if c.IsInter(cp) {
-                //Intersection
-                displace := c.Pos.Sub(cp.Pos).SetMag((c.Radius + cp.Radius - dist) / 2)
-                c.Pos.AddP(displace)
-                cp.Pos.AddP(displace.Mult(-1))
-                // Momentum
-                cVelP := c.Vel.Sub(c.Pos.Sub(cp.Pos).Mult((2 * cp.Mass / (c.Mass + cp.Mass)) * c.Vel.Sub(cp.Vel).Dot(c.Pos.Sub(cp.Pos)) / c.Pos.DistSq(cp.Pos)))
-                cp.Vel = cp.Vel.Sub(cp.Pos.Sub(c.Pos).Mult((2 * c.Mass / (c.Mass + cp.Mass)) * cp.Vel.Sub(c.Vel).Dot(cp.Pos.Sub(c.Pos)) / c.Pos.DistSq(cp.Pos)))
-                c.Vel = cVelP
-            }
+        c.CollideWith(&corpi[idx], 1)
+    }
+}
+
+// CollideSwept collides the given corpus with cp if they touch anywhere
+// along their paths over the next timestep dt, so fast corpi cannot pass
+// through each other between steps. Like BounceSwept it is meant to be
+// called right before Update: both corpi are resolved at the time of
+// impact and moved back along their new velocities so that Update carries
+// them the rest of the step. Returns whether the corpi collided.
+func (c *Corpus) CollideSwept(cp *Corpus, e, dt float64) bool {
+    w := c.invMass() + cp.invMass()
+    if cp == c || c.Immaterial || cp.Immaterial || w == 0 || !c.CanCollide(cp) {
+        return false
+    }
+    t, ok := c.TimeOfImpact(cp, dt)
+    if !ok {
+        return false
+    }
+    if t == 0 {
+        c.CollideWith(cp, e)
+        return true
+    }
+    c.Pos.AddP(c.moveVel().Mult(t))
+    cp.Pos.AddP(cp.moveVel().Mult(t))
+    c.respond(cp, e, c.invMass()/w)
+    c.Pos.SubP(c.moveVel().Mult(t))
+    cp.Pos.SubP(cp.moveVel().Mult(t))
+    return true
+}
+
+// CollideWith collides the given corpus with cp using the coefficient of
+// restitution e, from 0 for perfectly inelastic to 1 for perfectly elastic.
+// Mutates velocities to preserve momentum, scaling the relative velocity
+// along the collision normal by e. Velocities only change while the corpi
+// are approaching each other.
+// Also prevents intersections by directly mutating positions, moving each
+// corpus in proportion to its inverse mass. A static corpus has infinite
+// mass, so it never moves and the other corpus rebounds off it fully.
+// A corpus never collides with itself, nor with one on a layer outside
+// its mask, see CanCollide.
+func (c *Corpus) CollideWith(cp *Corpus, e float64) {
+    c.collide(cp, e)
+}
+
+// collide is CollideWith, returning whether the corpi collided.
+func (c *Corpus) collide(cp *Corpus, e float64) bool {
+    w := c.invMass() + cp.invMass()
+    if cp != c && !c.Immaterial && !cp.Immaterial && w != 0 && c.CanCollide(cp) {
+        sep := c.Pos.Sub(cp.Pos)
+        dist := sep.Mag()
+        // There is a collision.
+        if dist <= c.Radius+cp.Radius {
+            // Shares of the response, m2/(m1+m2) and m1/(m1+m2).
+            cShare := c.invMass() / w
+            cpShare := 1 - cShare
+            //Intersection
+            displace := sep.SetMag(c.Radius + cp.Radius - dist)
+            c.Pos.AddP(displace.Mult(cShare))
+            cp.Pos.AddP(displace.Mult(-cpShare))
+            c.respond(cp, e, cShare)
+            return true
+        }
+    }
+    return false
+}
+
+// respond mutates the velocities of the touching corpi c and cp with
+// restitution e, where cShare is the share m2/(m1+m2) of the response
+// taken by c. Velocities only change while the corpi are approaching.
+// Solving the equation for a 2D collision with restitution yields:
+// v1' = v1 - ((1+e)*m2/(m1+m2)) * (<v1-v2, x1-x2>)/(||x1-x2||^2) * (x1-x2)
+// v2' = v2 - ((1+e)*m1/(m1+m2)) * (<v2-v1, x2-x1>)/(||x2-x1||^2) * (x2-x1)
+// As x2-x1 = -(x1-x2) and <v2-v1, x2-x1> = <v1-v2, x1-x2>, both share
+// the separation x1-x2, its squared length and the dot product:
+// v2' = v2 + ((1+e)*m1/(m1+m2)) * (<v1-v2, x1-x2>)/(||x1-x2||^2) * (x1-x2)
+// The changes of momentum m1*(v1'-v1) and m2*(v2'-v2) then cancel out.
+func (c *Corpus) respond(cp *Corpus, e, cShare float64) {
+    cpShare := 1 - cShare
+    sep := c.Pos.Sub(cp.Pos)
+    approach := c.Vel.Sub(cp.Vel).Dot(sep)
+    // Momentum, only while approaching.
+    if approach < 0 {
+        distSq := sep.MagSq()
+        c.Vel.SubP(sep.Mult(((1 + e) * cShare) * approach / distSq))
+        cp.Vel.AddP(sep.Mult(((1 + e) * cpShare) * approach / distSq))
+    }
+}
+
+// ResolveCollisions collides every pair of corpi in the slice exactly once
+// with the coefficient of restitution e, as in CollideWith.
+func ResolveCollisions(corpi []Corpus, e float64) {
+    for i := range corpi {
+        for j := i + 1; j < len(corpi); j++ {
+            corpi[i].CollideWith(&corpi[j], e)
         }
     }
 }
 
-// Coulomb calculates and applies the electrostatic force
-// between the given corpus and all of the rest corpi.
+// SolveDistance repositions the two corpi along the axis connecting them
+// so that their separation equals length, like a rigid rod.
+// The correction is split by inverse mass, so the lighter corpus moves more
+// and a static corpus does not move. Velocities are left untouched.
+func SolveDistance(a, b *Corpus, length float64) {
+    n := a.Pos.DirectionTo(b.Pos)
+    wa, wb := a.invMass(), b.invMass()
+    if wa+wb == 0 {
+        return
+    }
+    delta := (a.Pos.Dist(b.Pos) - length) / (wa + wb)
+    a.Pos.AddP(n.Mult(delta * wa))
+    b.Pos.SubP(n.Mult(delta * wb))
+}
+
+// Contains reports whether the point p lies within the disk of the corpus,
+// boundary included.
+func (c Corpus) Contains(p Vector) bool {
+    return c.Pos.Dist(p) <= c.Radius
+}
+
+// ContainsSq is like Contains but compares squared distances,
+// avoiding the square root in hot loops.
+func (c Corpus) ContainsSq(p Vector) bool {
+    return c.Pos.DistSq(p) <= c.Radius*c.Radius
+}
+
+// Coulomb calculates the electrostatic force between the given corpus and
+// all of the rest corpi, and accumulates it with AddForce.
 // Using Coulomb's law:
 // F = k*q1*q2/r^2
+// Unlike the Apply methods it leaves Acc alone: the force only shows in
+// Force until ApplyNetForce or an Update applies it.
+// The force is applied to both corpi of each pair, so calling Coulomb
+// for every corpus of a slice counts each pair twice; use CoulombAll for
+// that instead. The corpus itself is skipped if it is in the slice.
 func (c *Corpus) Coulomb(corpi []Corpus, k float64) {
     for idx := range corpi {
         cp := &corpi[idx]
-        if !c.Immaterial && !cp.Immaterial {
-            dist := c.Pos.Dist(cp.Pos)
-            if dist+2 >= c.Radius+cp.Radius {
-                force := cp.Pos.Sub(c.Pos).Mult(1 * c.Charge * cp.Charge / (dist * dist)).Div(dist)
-                c.ApplyForce(force.Mult(-1))
-                cp.ApplyForce(force)
-            }
+        if force, ok := c.coulomb(cp, k); ok {
+            c.AddForce(force)
+            cp.AddForce(force.Neg())
+        }
+    }
+}
+
+// coulomb returns the electrostatic force on c from cp, and false if they
+// do not interact.
+func (c *Corpus) coulomb(cp *Corpus, k float64) (Vector, bool) {
+    if cp != c && !c.Immaterial && !cp.Immaterial {
+        dist := c.Pos.Dist(cp.Pos)
+        if dist+2 >= c.Radius+cp.Radius {
+            return cp.Pos.Sub(c.Pos).Mult(k * c.Charge * cp.Charge / (dist * dist)).Div(dist).Neg(), true
         }
     }
+    return Vector{}, false
+}
+
+// CoulombAll calculates and accumulates the electrostatic force between
+// every pair of corpi in the slice exactly once.
+func CoulombAll(corpi []Corpus, k float64) {
+    for idx := range corpi {
+        corpi[idx].Coulomb(corpi[idx+1:], k)
+    }
+}
+
+// Fragment splits the corpus into n equal fragments scattered inside it,
+// conserving its total mass, charge, momentum and area. The fragments fly
+// apart in random directions drawn from r, with speeds relative to the
+// corpus up to 2*spread.
+func (c Corpus) Fragment(n int, spread float64, r *rand.Rand) []Corpus {
+    if n <= 0 {
+        return nil
+    }
+    dirs := make([]Vector, n)
+    var mean Vector
+    for idx := range dirs {
+        dirs[idx] = RandUnit(r)
+        mean.AddP(dirs[idx].Div(float64(n)))
+    }
+
+    // Subtracting the mean keeps both the center of mass and the momentum
+    // where they were. The offsets, up to 2 long, are scaled down together
+    // so that every fragment stays inside the corpus.
+    longest := 1.0
+    for idx := range dirs {
+        dirs[idx].SubP(mean)
+        longest = math.Max(longest, dirs[idx].Mag())
+    }
+
+    rad := c.Radius / math.Sqrt(float64(n))
+    frags := make([]Corpus, n)
+    for idx := range frags {
+        dir := dirs[idx]
+        frag := c
+        frag.Pos = c.Pos.Add(dir.Mult((c.Radius - rad) / longest))
+        frag.Vel = c.Vel.Add(dir.Mult(spread))
+        frag.Mass = c.Mass / float64(n)
+        frag.Charge = c.Charge / float64(n)
+        frag.Force = c.Force.Div(float64(n))
+        frag.Radius = rad
+        frag.verlet = false
+        frag.Wake()
+        frags[idx] = frag
+    }
+    return frags
 }
 
-// Gravitate calculates and applies the gravitational force
-// between the given corpus and all of the rest corpi.
+// Gravitate calculates the gravitational force between the given corpus
+// and all of the rest corpi, and accumulates it with AddForce.
 // Using Newton's law of universal gravitation:
 // F = G*m1*m2/r^2
+// Unlike the Apply methods it leaves Acc alone: the force only shows in
+// Force until ApplyNetForce or an Update applies it.
 func (c *Corpus) Gravitate(corpi []Corpus, G float64) {
+    c.GravitateSoftened(corpi, G, 0)
+}
+
+// GravitateSoftened is like Gravitate but with a Plummer softening length
+// eps, which keeps the force finite as corpi get arbitrarily close.
+// F = G*m1*m2*r/(r^2+eps^2)^(3/2)
+// Like Coulomb, the force is applied to both corpi of each pair; use
+// GravitateAll to apply it once per pair over a whole slice.
+// The corpus itself is skipped if it is in the slice.
+func (c *Corpus) GravitateSoftened(corpi []Corpus, G, eps float64) {
     for idx := range corpi {
         cp := &corpi[idx]
-        if !c.Immaterial && !cp.Immaterial {
-            dist := c.Pos.Dist(cp.Pos)
-            if dist+2 >= c.Radius+cp.Radius {
-                force := cp.Pos.Sub(c.Pos).Mult(G * c.Mass * cp.Mass / (dist * dist)).Div(dist)
-                c.ApplyForce(force)
-                cp.ApplyForce(force.Mult(-1))
-            }
+        if force, ok := c.gravity(cp, G, eps); ok {
+            c.AddForce(force)
+            cp.AddForce(force.Neg())
         }
     }
 }
 
+// gravity returns the softened gravitational force on c from cp, and false
+// if they do not interact.
+func (c *Corpus) gravity(cp *Corpus, G, eps float64) (Vector, bool) {
+    if cp != c && !c.Immaterial && !cp.Immaterial {
+        dist := c.Pos.Dist(cp.Pos)
+        if dist+2 >= c.Radius+cp.Radius {
+            distSq := dist*dist + eps*eps
+            return cp.Pos.Sub(c.Pos).Mult(G * c.Mass * cp.Mass / distSq).Div(math.Sqrt(distSq)), true
+        }
+    }
+    return Vector{}, false
+}
+
+// GravitateAll calculates and accumulates the softened gravitational force
+// between every pair of corpi in the slice exactly once.
+func GravitateAll(corpi []Corpus, G, eps float64) {
+    for idx := range corpi {
+        corpi[idx].GravitateSoftened(corpi[idx+1:], G, eps)
+    }
+}
+
+// GravitateToward calculates and applies the gravitational force pulling
+// the given corpus toward a fixed center with gravitational parameter mu.
+// F = -mu*m*(x-center)/r^3
+// The distance is never taken below the corpus radius, so the force stays
+// finite as the corpus passes through the center.
+func (c *Corpus) GravitateToward(center Vector, mu float64) {
+    if !c.Immaterial {
+        r := center.Sub(c.Pos)
+        dist := math.Max(r.Mag(), c.Radius)
+        if dist > 0 {
+            c.ApplyForce(r.Mult(mu * c.Mass / (dist * dist * dist)))
+        }
+    }
+}
+
+// KineticEnergy returns the kinetic energy of the corpus, "E = m*v^2/2".
+func (c Corpus) KineticEnergy() float64 {
+    return 0.5 * c.Mass * c.Vel.MagSq()
+}
+
+// MaxSpeed returns the maximum speed of the corpus, 0 if unlimited.
+func (c Corpus) MaxSpeed() float64 {
+    return c.maxSpeed
+}
+
+// Merge returns the corpus formed by the two corpi coalescing, conserving
+// their total mass, charge and momentum. It lies at their center of mass,
+// with the radius preserving their total area, "r = sqrt(ra^2+rb^2)", and
+// the tag, user data, color, collision layer and mask, immateriality,
+// maximum speed and life of the heavier one. Massless corpi merge at
+// their midpoint. If one is static, so is the result, and it stays where
+// that one was.
+func Merge(a, b Corpus) Corpus {
+    if b.Mass > a.Mass {
+        a, b = b, a
+    }
+    m := Corpus{
+        Mass:       a.Mass + b.Mass,
+        Charge:     a.Charge + b.Charge,
+        Radius:     math.Sqrt(a.Radius*a.Radius + b.Radius*b.Radius),
+        Static:     a.Static || b.Static,
+        Tag:        a.Tag,
+        UserData:   a.UserData,
+        Color:      a.Color,
+        Layer:      a.Layer,
+        Mask:       a.Mask,
+        Immaterial: a.Immaterial,
+        Life:       a.Life,
+        maxSpeed:   a.maxSpeed,
+        mortal:     a.mortal,
+    }
+    if m.Mass != 0 {
+        m.Pos = a.Pos.Mult(a.Mass).Add(b.Pos.Mult(b.Mass)).Div(m.Mass)
+        m.Vel = a.Momentum().Add(b.Momentum()).Div(m.Mass)
+        m.Acc = a.Acc.Mult(a.Mass).Add(b.Acc.Mult(b.Mass)).Div(m.Mass)
+        m.Force = a.Force.Add(b.Force)
+    } else {
+        m.Pos = a.Pos.Midpoint(b.Pos)
+        m.Vel = a.Vel.Midpoint(b.Vel)
+    }
+    if a.Static != b.Static {
+        if b.Static {
+            a = b
+        }
+        m.Pos = a.Pos
+    }
+    if m.Static {
+        m.Vel, m.Acc, m.Force = Vector{}, Vector{}, Vector{}
+    }
+    return m
+}
+
+// Momentum returns the linear momentum of the corpus, "p = m*v".
+func (c Corpus) Momentum() Vector {
+    return c.Vel.Mult(c.Mass)
+}
+
+// Overlap returns the penetration depth of the two corpi,
+// (r1+r2) - ||x1-x2||, or 0 if they do not overlap.
+// Unlike IsInter, merely touching corpi do not overlap.
+func (c Corpus) Overlap(cp Corpus) float64 {
+    return math.Max(0, c.Radius+cp.Radius-c.Pos.Dist(cp.Pos))
+}
+
 // Instead of bouncing off the window boundaries, Pacman makes the particle
 // reappear on the opposite border.
 func (c *Corpus) Pacman(width, height float64) {
@@ -167,106 +807,263 @@ func (c *Corpus) Pacman(width, height float64) {
 
 }
 
+// SetLife gives the corpus the given time to live, after which it is
+// dead. The Update methods count it down even for static or immaterial
+// corpi, and a World removes dead corpi. A negative life makes the corpus
+// immortal again.
+func (c *Corpus) SetLife(life float64) {
+    c.Life = life
+    c.mortal = life >= 0
+}
+
+// SetMaxSpeed sets the maximum speed of the corpus, which the Update
+// methods clamp its velocity to, like a terminal velocity. 0 or less
+// removes the limit.
+func (c *Corpus) SetMaxSpeed(v float64) {
+    c.maxSpeed = math.Max(v, 0)
+}
+
+// String returns a readable summary of the corpus in the stable form
+// "Corpus{pos: (x, y), vel: (x, y), mass: m, radius: r}".
+func (c Corpus) String() string {
+    return fmt.Sprintf("Corpus{pos: %v, vel: %v, mass: %.3f, radius: %.3f}", c.Pos, c.Vel, c.Mass, c.Radius)
+}
+
+// TimeOfImpact returns the earliest time within the next timestep dt at
+// which the two corpi touch when moving at their current velocities, and
+// whether they touch at all. Overlapping corpi touch at time 0.
+// Solving ||(x2-x1) + (v2-v1)*t|| = r1+r2 for the smallest t.
+func (c Corpus) TimeOfImpact(cp *Corpus, dt float64) (float64, bool) {
+    d := cp.Pos.Sub(c.Pos)
+    v := cp.moveVel().Sub(c.moveVel())
+    rad := c.Radius + cp.Radius
+    cc := d.MagSq() - rad*rad
+    if cc <= 0 {
+        return 0, true
+    }
+    a := v.MagSq()
+    b := d.Dot(v)
+    if a == 0 || b >= 0 { // not approaching
+        return 0, false
+    }
+    disc := b*b - a*cc
+    if disc < 0 {
+        return 0, false
+    }
+    t := (-b - math.Sqrt(disc)) / a
+    if t > dt {
+        return 0, false
+    }
+    return t, true
+}
+
 // Update updates the given corpus by mutating its physical attributes as unit time passes.
+// A static corpus never moves.
 func (c *Corpus) Update() {
-    if !c.Immaterial {
-        c.Vel.AddP(c.Acc) // a = dv/dt
-        c.Pos.AddP(c.Vel) // v = dx/dt
-        c.Acc.MultP(0)    // resets acceleration
+    c.UpdateDt(1)
+}
+
+// UpdateDt updates the given corpus by mutating its physical attributes as
+// time dt passes, using semi-implicit Euler integration. The velocity is
+// clamped to the maximum speed, if any, before moving the corpus.
+// A static corpus never moves.
+func (c *Corpus) UpdateDt(dt float64) {
+    c.verlet = false
+    c.age(dt)
+    c.ApplyNetForce()
+    if !c.Immaterial && !c.Static {
+        c.Vel.AddP(c.Acc.Mult(dt)) // a = dv/dt
+        c.clampSpeed()
+        c.Pos.AddP(c.Vel.Mult(dt)) // v = dx/dt
+        c.Acc.MultP(0)             // resets acceleration
     }
 }
 
-// Add adds two vectors, returning a new vector.
-func (a Vector) Add(b Vector) Vector {
-    return Vector{a.X + b.X, a.Y + b.Y}
+// age counts the life of the corpus down by dt, if it has one.
+func (c *Corpus) age(dt float64) {
+    if c.mortal {
+        c.Life -= dt
+    }
 }
 
-// AddI adds two vectors in place.
-func (a *Vector) AddP(b Vector) {
-    a.X = a.X + b.X
-    a.Y = a.Y + b.Y
+// clampSpeed limits the speed of the corpus to its maximum speed, if any.
+func (c *Corpus) clampSpeed() {
+    if c.maxSpeed > 0 {
+        c.Vel.LimitP(c.maxSpeed)
+    }
 }
 
-// AngleBetween returns the angle between the two vectors in radians.
-func (a Vector) AngleBetween(b Vector) float64 {
-    return math.Acos(a.Dot(b) / (a.Mag() * b.Mag()))
+// UpdateRK4 updates the given corpus by mutating its physical attributes
+// as time dt passes, using classic 4th-order Runge-Kutta integration of
+// the force returned by the given function of position and velocity.
+// Any acceleration already in Acc is added as a constant over the step.
+// A static corpus never moves.
+func (c *Corpus) UpdateRK4(dt float64, force func(pos, vel Vector) Vector) {
+    c.age(dt)
+    c.ApplyNetForce()
+    if !c.Immaterial && !c.Static {
+        acc := func(pos, vel Vector) Vector {
+            return c.Acc.Add(force(pos, vel).Mult(c.invMass()))
+        }
+        k1x, k1v := c.Vel, acc(c.Pos, c.Vel)
+        k2x := c.Vel.Add(k1v.Mult(dt / 2))
+        k2v := acc(c.Pos.Add(k1x.Mult(dt/2)), k2x)
+        k3x := c.Vel.Add(k2v.Mult(dt / 2))
+        k3v := acc(c.Pos.Add(k2x.Mult(dt/2)), k3x)
+        k4x := c.Vel.Add(k3v.Mult(dt))
+        k4v := acc(c.Pos.Add(k3x.Mult(dt)), k4x)
+        c.Pos.AddP(k1x.Add(k2x.Mult(2)).Add(k3x.Mult(2)).Add(k4x).Mult(dt / 6))
+        c.Vel.AddP(k1v.Add(k2v.Mult(2)).Add(k3v.Mult(2)).Add(k4v).Mult(dt / 6))
+        c.clampSpeed()
+        c.Acc.MultP(0) // resets acceleration
+    }
 }
 
-// Dist returns the distance between the two vectors as a float64 number.
-func (a Vector) Dist(b Vector) float64 {
-    return a.Sub(b).Mag()
+// UpdateVerlet updates the given corpus by mutating its physical attributes
+// as time dt passes, using velocity Verlet integration, which conserves
+// energy far better than UpdateDt for orbits and other conservative forces.
+// Acc must hold the acceleration at the current position, as with UpdateDt.
+// The acceleration is kept until the next call to complete the velocity
+// update "v' = v + (a+a')*dt/2", so between calls Vel holds the prediction
+// "v + a*dt", and calls should not be mixed with UpdateDt.
+// A static corpus never moves.
+func (c *Corpus) UpdateVerlet(dt float64) {
+    c.age(dt)
+    c.ApplyNetForce()
+    if !c.Immaterial && !c.Static {
+        if c.verlet {
+            c.Vel.AddP(c.Acc.Sub(c.prevAcc).Mult(dt / 2)) // v = v + (a+a')*dt/2
+        }
+        c.Pos.AddP(c.Vel.Mult(dt).Add(c.Acc.Mult(dt * dt / 2))) // x = x + v*dt + a*dt^2/2
+        c.Vel.AddP(c.Acc.Mult(dt))                              // predicts v + a*dt
+        c.clampSpeed()
+        c.prevAcc = c.Acc
+        c.verlet = true
+        c.Acc.MultP(0) // resets acceleration
+    }
 }
 
-// DistSq returns the distance between the two vectors, squared as a float64 number.
-func (a Vector) DistSq(b Vector) float64 {
-    return a.Sub(b).MagSq()
+// Wake wakes the corpus up if it is asleep.
+func (c *Corpus) Wake() {
+    c.asleep = false
+    c.idle = 0
 }
 
-// Div divides the vector with a scalar(float64), returning a new vector.
-func (a Vector) Div(b float64) Vector {
-    return Vector{a.X / b, a.Y / b}
+// Clamp returns x clamped into [lo, hi].
+func Clamp(x, lo, hi float64) float64 {
+    return math.Max(lo, math.Min(hi, x))
 }
 
-// DivP divides the vector with a scalar(float64) in place.
-func (a *Vector) DivP(b float64) {
-    a.X = a.X / b
-    a.Y = a.Y / b
+// DegToRad converts the angle d from degrees to radians.
+func DegToRad(d float64) float64 {
+    return d * math.Pi / 180
 }
 
-// Dot returns the dot product of two vectors.
-func (a Vector) Dot(b Vector) float64 {
-    return a.X*b.X + a.Y*b.Y
+// Lerp linearly interpolates between a and b by t.
+// t is not clamped, so values outside [0, 1] extrapolate.
+func Lerp(a, b, t float64) float64 {
+    return a + (b-a)*t
 }
 
-// Mag returns the magnitude of the vector
-func (a Vector) Mag() float64 {
-    return math.Sqrt(a.X*a.X + a.Y*a.Y)
+// RadToDeg converts the angle r from radians to degrees.
+func RadToDeg(r float64) float64 {
+    return r * 180 / math.Pi
 }
 
-// MagSq returns the magnitude of the vector, squared.
-func (a Vector) MagSq() float64 {
-    return a.X*a.X + a.Y*a.Y
+// WrapAngle returns the angle a in radians wrapped into (-Pi, Pi].
+// Angles already in range are returned unchanged.
+func WrapAngle(a float64) float64 {
+    if a > -math.Pi && a <= math.Pi {
+        return a
+    }
+    a = math.Mod(a+math.Pi, 2*math.Pi)
+    if a <= 0 {
+        a += 2 * math.Pi
+    }
+    return a - math.Pi
 }
 
-// Mult multiplies the vector with a scalar(float64), returning a new vector.
-func (a Vector) Mult(b float64) Vector {
-    return Vector{a.X * b, a.Y * b}
+// AddSlice stores a[i] + b[i] in dst[i] for every i, without allocating,
+// so buffers can be reused across steps. dst may be a or b. It panics if
+// the slices differ in length.
+func AddSlice(dst, a, b []Vector) {
+    if len(a) != len(dst) || len(b) != len(dst) {
+        panic("corpus: AddSlice of slices of different lengths")
+    }
+    for i := range dst {
+        dst[i] = Vector{a[i].X + b[i].X, a[i].Y + b[i].Y}
+    }
 }
 
-// MultP multiplies the vector with a scalar(float64) in place.
-func (a *Vector) MultP(b float64) {
-    a.X = a.X * b
-    a.Y = a.Y * b
+// FromComplex returns the vector {real(z), imag(z)}.
+func FromComplex(z complex128) Vector {
+    return Vector{real(z), imag(z)}
 }
 
-// Norm normalizes a vector, returning a new vector.
-func (a Vector) Norm() Vector {
-    return a.Div(a.Mag())
+// FromPolar returns a vector with the given magnitude and angle theta
+// in radians, measured counter-clockwise from the positive X axis.
+// It is the inverse of Mag and Angle.
+func FromPolar(mag, theta float64) Vector {
+    sin, cos := math.Sincos(theta)
+    return Vector{mag * cos, mag * sin}
 }
 
-// NormP normalizes a vector in place.
-func (a *Vector) NormP() {
-    a.DivP(a.Mag())
+// FromSlice returns the vector {s[0], s[1]}.
+// Returns an error if s does not have exactly two elements.
+func FromSlice(s []float64) (Vector, error) {
+    if len(s) != 2 {
+        return Vector{}, fmt.Errorf("corpus: FromSlice needs 2 elements, got %d", len(s))
+    }
+    return Vector{s[0], s[1]}, nil
 }
 
-// SetMag returns a new vector in the same direction with given magnitude.
-func (a Vector) SetMag(mag float64) Vector {
-    return a.Norm().Mult(mag)
+// Orientation returns the turn direction of the points a, b, c:
+// +1 for counter-clockwise (a left turn), -1 for clockwise and
+// 0 for collinear, using the sign of the cross product of b-a and c-a.
+func Orientation(a, b, c Vector) int {
+    cross := b.Sub(a).Cross(c.Sub(a))
+    switch {
+    case cross > 0:
+        return 1
+    case cross < 0:
+        return -1
+    }
+    return 0
 }
 
-// SetMagP mutates the magnitude of the vector, keeping the direction.
-func (a *Vector) SetMagP(mag float64) {
-    a.NormP()
-    a.MultP(mag)
+// RandUnit returns a unit vector pointing in a uniformly distributed
+// random direction, drawn from the given source.
+func RandUnit(r *rand.Rand) Vector {
+    return FromPolar(1, r.Float64()*2*math.Pi)
 }
 
-// Sub subtracts a from b, returning a new vector.
-func (a Vector) Sub(b Vector) Vector {
-    return Vector{a.X - b.X, a.Y - b.Y}
+// ScaleSlice stores a[i] * s in dst[i] for every i, without allocating.
+// dst may be a. It panics if the slices differ in length.
+func ScaleSlice(dst, a []Vector, s float64) {
+    if len(a) != len(dst) {
+        panic("corpus: ScaleSlice of slices of different lengths")
+    }
+    for i := range dst {
+        dst[i] = Vector{a[i].X * s, a[i].Y * s}
+    }
 }
 
-// SubP subtracts a from b in place.
-func (a *Vector) SubP(b Vector) {
-    a.X = a.X - b.X
-    a.Y = a.Y - b.Y
+// SegmentIntersect returns the point where the segment p1-p2 crosses the
+// segment q1-q2, and whether they cross at all. Touching endpoints count
+// as crossing. Parallel segments, including collinear overlapping ones,
+// return false since they have no single intersection point.
+func SegmentIntersect(p1, p2, q1, q2 Vector) (Vector, bool) {
+    r := p2.Sub(p1)
+    s := q2.Sub(q1)
+    denom := r.Cross(s)
+    if denom == 0 {
+        return Vector{}, false
+    }
+    pq := q1.Sub(p1)
+    t := pq.Cross(s) / denom // position along p1-p2
+    u := pq.Cross(r) / denom // position along q1-q2
+    if t < 0 || t > 1 || u < 0 || u > 1 {
+        return Vector{}, false
+    }
+    return p1.Add(r.Mult(t)), true
 }
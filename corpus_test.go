@@ -1,7 +1,9 @@
 package corpus
 
 import (
+	"image/color"
 	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -10,8 +12,10 @@ func TestMakeCorpus(t *testing.T) {
 	posY := 2.0
 	velX := 3.0
 	velY := 4.0
-	rad := 5.0
-	c := MakeCorpus(posX, posY, velX, velY, rad)
+	mass := 5.0
+	charge := 6.0
+	rad := 7.0
+	c := MakeCorpus(posX, posY, velX, velY, mass, charge, rad)
 
 	if c.Pos.X != posX {
 		t.Error("WRONG POSX")
@@ -29,12 +33,119 @@ func TestMakeCorpus(t *testing.T) {
 		t.Error("WRONG VELY")
 	}
 
+	if c.Mass != mass {
+		t.Error("WRONG MASS")
+	}
+
+	if c.Charge != charge {
+		t.Error("WRONG CHARGE")
+	}
+
 	if c.Radius != rad {
 		t.Error("WRONG RAD")
 	}
 
 }
 
+func TestNewCorpus(t *testing.T) {
+	c := NewCorpus()
+
+	if c.Pos != (Vector{0, 0}) || c.Vel != (Vector{0, 0}) || c.Acc != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	if c.Mass != 1 || c.Radius != 1 || c.Charge != 0 || c.Immaterial {
+		t.Error("WRONG !!")
+	}
+
+	c = NewCorpus(WithPos(Vector{1, 2}), WithVel(Vector{3, 4}), WithMass(5), WithCharge(6), WithRadius(7), WithImmaterial(true))
+
+	if c.Pos != (Vector{1, 2}) || c.Vel != (Vector{3, 4}) {
+		t.Error("WRONG !!")
+	}
+
+	if c.Mass != 5 || c.Charge != 6 || c.Radius != 7 || !c.Immaterial {
+		t.Error("WRONG !!")
+	}
+
+	if NewCorpus().Static || !NewCorpus(WithStatic(true)).Static {
+		t.Error("WRONG !!")
+	}
+
+	red := color.RGBA{255, 0, 0, 255}
+	white := color.RGBA{255, 255, 255, 255}
+
+	if NewCorpus().Color != white || MakeCorpus(0, 0, 0, 0, 1, 0, 1).Color != white || NewCorpus(WithColor(red)).Color != red {
+		t.Error("WRONG !!")
+	}
+
+	m := MakeCorpus(1, 2, 3, 4, 5, 6, 7)
+	m.Immaterial = true
+
+	if c != m {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_AddForce(t *testing.T) {
+	c := Corpus{Mass: 2}
+	c.AddForce(Vector{3, 0})
+	c.AddForce(Vector{1, -4})
+
+	if c.Force != (Vector{4, -4}) || !c.Acc.IsZero() {
+		t.Error("WRONG !!")
+	}
+
+	c.ApplyNetForce()
+
+	if c.Acc != (Vector{2, -2}) || !c.Force.IsZero() {
+		t.Error("WRONG !!")
+	}
+
+	// Updates apply it too.
+	c = Corpus{Mass: 2}
+	c.AddForce(Vector{4, 0})
+	c.UpdateDt(1)
+
+	if c.Vel != (Vector{2, 0}) || !c.Force.IsZero() || !c.Acc.IsZero() {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_ApplyDrag(t *testing.T) {
+	c := Corpus{Vel: Vector{10, 5}, Mass: 2, Radius: 1}
+	speed := c.Vel.Mag()
+
+	for i := 0; i < 100; i++ {
+		c.ApplyDrag(0.5)
+		c.Update()
+
+		if c.Vel.Mag() >= speed {
+			t.Error("WRONG !!")
+		}
+		speed = c.Vel.Mag()
+	}
+
+	if speed > 0.001 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_ApplyDragQuadratic(t *testing.T) {
+	c := Corpus{Vel: Vector{10, 5}, Mass: 2, Radius: 1}
+	speed := c.Vel.Mag()
+
+	for i := 0; i < 100; i++ {
+		c.ApplyDragQuadratic(0.1)
+		c.Update()
+
+		if c.Vel.Mag() >= speed {
+			t.Error("WRONG !!")
+		}
+		speed = c.Vel.Mag()
+	}
+}
+
 func TestCorpus_ApplyForce(t *testing.T) {
 	pos := Vector{0, 0}
 	vel := Vector{0, 0}
@@ -43,7 +154,7 @@ func TestCorpus_ApplyForce(t *testing.T) {
 	mass := 1.0
 	radius := 1.0
 
-	c := Corpus{pos, vel, acc, mass, radius}
+	c := Corpus{Pos: pos, Vel: vel, Acc: acc, Mass: mass, Radius: radius}
 
 	c.ApplyForce(Vector{1, 1})
 
@@ -54,117 +165,1536 @@ func TestCorpus_ApplyForce(t *testing.T) {
 	}
 }
 
-func TestCorpus_Update(t *testing.T) {
-	pos := Vector{0, 0}
-	vel := Vector{1, 1}
-	acc := Vector{0, 0}
+func TestCorpus_ApplyForce_ZeroMass(t *testing.T) {
+	c := Corpus{Vel: Vector{1, 0}, Radius: 1}
+	c.ApplyForce(Vector{1, 1})
 
-	mass := 1.0
-	radius := 1.0
+	if c.Acc != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	c.Update()
+
+	if !c.Pos.IsValid() || !c.Vel.IsValid() {
+		t.Error("WRONG !!")
+	}
+
+	cp := Corpus{Pos: Vector{1.5, 0}, Vel: Vector{-1, 0}, Mass: 1, Radius: 1}
+	c.CollideWith(&cp, 1)
+
+	if !c.Pos.IsValid() || !c.Vel.IsValid() || !cp.Pos.IsValid() || !cp.Vel.IsValid() {
+		t.Error("WRONG !!")
+	}
+
+	cp = Corpus{Pos: c.Pos, Radius: 1}
+	c.CollideWith(&cp, 1)
+
+	if !c.Pos.IsValid() || !cp.Pos.IsValid() {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_ApplyForce_NegativeMass(t *testing.T) {
+	c := Corpus{Vel: Vector{1, 0}, Mass: -2, Radius: 1}
+	c.ApplyForce(Vector{1, 1})
+	c.ApplyImpulse(Vector{1, 1})
+
+	if c.Acc != (Vector{0, 0}) || c.Vel != (Vector{1, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	// A negative mass is infinite like a static one: the other corpus
+	// takes the whole response.
+	c = Corpus{Pos: Vector{0, 0}, Mass: -2, Radius: 1}
+	cp := Corpus{Pos: Vector{1.5, 0}, Vel: Vector{-1, 0}, Mass: 1, Radius: 1}
+	c.CollideWith(&cp, 1)
+
+	if c.Pos != (Vector{0, 0}) || c.Vel != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	if !cp.Pos.Equals(Vector{2, 0}, Epsilon) || !cp.Vel.Equals(Vector{1, 0}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_ApplyGravity(t *testing.T) {
+	c := Corpus{Mass: 5, Radius: 1}
+	g := Vector{0, -9.8}
+	c.ApplyGravity(g)
+
+	if !c.Acc.Equals(g, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	c.Update()
+
+	if !c.Vel.Equals(g, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_ApplyImpulse(t *testing.T) {
+	c := Corpus{Vel: Vector{1, 1}, Mass: 2, Radius: 1}
+	c.ApplyImpulse(Vector{2, 0})
+
+	if c.Vel != (Vector{2, 1}) || c.Acc != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	m := Corpus{Vel: Vector{1, 1}, Radius: 1}
+	m.ApplyImpulse(Vector{2, 0})
+
+	if m.Vel != (Vector{1, 1}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_ApplyMagnetic(t *testing.T) {
+	c := Corpus{Vel: Vector{2, 0}, Mass: 1, Charge: 1.5}
+	c.ApplyMagnetic(2)
+
+	if c.Acc != (Vector{0, -6}) {
+		t.Error("WRONG !!")
+	}
+
+	// The force is always perpendicular to the velocity.
+	c = Corpus{Vel: Vector{-3, 4}, Mass: 1, Charge: -1}
+	c.ApplyMagnetic(0.5)
+
+	if c.Acc.Dot(c.Vel) != 0 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_ApplySpring(t *testing.T) {
+	c := Corpus{Pos: Vector{0, 0}, Mass: 1}
+	cp := Corpus{Pos: Vector{3, 0}, Mass: 2}
+	c.ApplySpring(&cp, 1, 2, 0)
+
+	// Stretched by 2, F = 2*2 pulling them together.
+	if c.Acc != (Vector{4, 0}) || cp.Acc != (Vector{-2, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	// Compressed springs push apart.
+	c = Corpus{Pos: Vector{0, 0}, Mass: 1}
+	cp = Corpus{Pos: Vector{0, 0.5}, Mass: 1}
+	c.ApplySpring(&cp, 1, 2, 0)
+
+	if c.Acc.Y >= 0 || cp.Acc.Y <= 0 {
+		t.Error("WRONG !!")
+	}
 
-	c := Corpus{pos, vel, acc, mass, radius}
+	// Damping opposes the corpi separating.
+	c = Corpus{Pos: Vector{0, 0}, Vel: Vector{-1, 0}, Mass: 1}
+	cp = Corpus{Pos: Vector{1, 0}, Vel: Vector{1, 0}, Mass: 1}
+	c.ApplySpring(&cp, 1, 2, 0.5)
+
+	if c.Acc != (Vector{1, 0}) || cp.Acc != (Vector{-1, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Bounce(t *testing.T) {
+	c := Corpus{Pos: Vector{9.5, 5}, Vel: Vector{2, 1}, Mass: 1, Radius: 1}
+
+	if c.Bounce(10, 10) != 1 {
+		t.Error("WRONG !!")
+	}
+
+	if c.Pos != (Vector{9, 5}) || c.Vel != (Vector{-2, 1}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Bounce_Large(t *testing.T) {
+	c := Corpus{Pos: Vector{3, 4}, Vel: Vector{1, -1}, Mass: 1, Radius: 8}
 
 	for i := 0; i < 10; i++ {
 		c.Update()
+		c.Bounce(10, 10)
+	}
+
+	if c.Pos != (Vector{5, 5}) || c.Vel != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	// Only the axis it does not fit along is held.
+	c = Corpus{Pos: Vector{20, 4}, Vel: Vector{1, -1}, Mass: 1, Radius: 8}
+	c.Bounce(40, 10)
+
+	if c.Pos != (Vector{20, 5}) || c.Vel != (Vector{1, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_BounceRestitution(t *testing.T) {
+	c := Corpus{Pos: Vector{5, 0.5}, Vel: Vector{1, -4}, Mass: 1, Radius: 1}
+
+	if c.BounceRestitution(10, 10, 0.8) != 1 {
+		t.Error("WRONG !!")
+	}
+
+	if c.Pos != (Vector{5, 1}) || !c.Vel.Equals(Vector{1, 0.8 * 4}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	// A dropped ball loses height on every bounce.
+	c = Corpus{Pos: Vector{5, 9}, Mass: 1, Radius: 1}
+	peak := c.Pos.Y
+	falling := true
+
+	for i := 0; i < 2000; i++ {
+		c.ApplyGravity(Vector{0, -0.01})
+		c.Update()
+		c.BounceRestitution(10, 10, 0.8)
+
+		if falling && c.Vel.Y > 0 {
+			falling = false
+		} else if !falling && c.Vel.Y <= 0 {
+			falling = true
+			if c.Pos.Y >= peak {
+				t.Error("WRONG !!")
+			}
+			peak = c.Pos.Y
+		}
+	}
+}
+
+func TestCorpus_BounceSwept(t *testing.T) {
+	c := Corpus{Pos: Vector{5, 5}, Vel: Vector{20, 0}, Mass: 1, Radius: 0.5}
+
+	if c.BounceSwept(10, 10, 1, 1) != 2 {
+		t.Error("WRONG !!")
+	}
+	c.Update()
+
+	if !c.Pos.Equals(Vector{7, 5}, Epsilon) || c.Vel != (Vector{20, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	c = Corpus{Pos: Vector{5, 5}, Vel: Vector{0, -6}, Mass: 1, Radius: 1}
+
+	if c.BounceSwept(10, 10, 0.5, 1) != 1 {
+		t.Error("WRONG !!")
+	}
+	c.Update()
+
+	if !c.Pos.Equals(Vector{5, 2}, Epsilon) || c.Vel != (Vector{0, 3}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Bounds(t *testing.T) {
+	c := Corpus{Pos: Vector{5, 5}, Mass: 1, Radius: 2}
+	min, max := c.Bounds()
+
+	if min != (Vector{3, 3}) || max != (Vector{7, 7}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_CanCollide(t *testing.T) {
+	const player, bullet = 1 << 1, 1 << 2
+	p := Corpus{Layer: player}
+	a := Corpus{Layer: bullet, Mask: player}
+	b := Corpus{Layer: bullet, Mask: player}
+	wall := Corpus{}
+
+	if !a.CanCollide(&p) || !p.CanCollide(&a) || a.CanCollide(&b) {
+		t.Error("WRONG !!")
+	}
+
+	// The default layer is not in the bullet mask, but every layer is in
+	// the default one.
+	if a.CanCollide(&wall) || !p.CanCollide(&wall) {
+		t.Error("WRONG !!")
+	}
+
+	// Bullets pass through each other but hit the player.
+	a = Corpus{Pos: Vector{0, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 1, Layer: bullet, Mask: player}
+	b = Corpus{Pos: Vector{1, 0}, Vel: Vector{-1, 0}, Mass: 1, Radius: 1, Layer: bullet, Mask: player}
+	a.CollideWith(&b, 1)
+
+	if a.Pos != (Vector{0, 0}) || a.Vel != (Vector{1, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	p = Corpus{Pos: Vector{1.5, 0}, Mass: 1, Radius: 1, Layer: player}
+	a.CollideWith(&p, 1)
+
+	if !a.Vel.Equals(Vector{0, 0}, Epsilon) || !p.Vel.Equals(Vector{1, 0}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCenterOfMass(t *testing.T) {
+	corpi := []Corpus{
+		MakeCorpus(0, 0, 0, 0, 1, 0, 1),
+		MakeCorpus(4, 0, 0, 0, 3, 0, 1),
+		MakeCorpus(100, 100, 0, 0, 0, 0, 1),
+		{Pos: Vector{-50, 7}, Mass: 10, Immaterial: true},
+	}
+
+	if !CenterOfMass(corpi).Equals(Vector{3, 0}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	if CenterOfMass(nil) != (Vector{0, 0}) || CenterOfMass(corpi[2:]) != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Clone(t *testing.T) {
+	c := MakeCorpus(1, 2, 3, 4, 5, 6, 7)
+	cl := c.Clone()
+
+	if cl != c {
+		t.Error("WRONG !!")
+	}
+
+	cl.Pos.AddP(Vector{1, 1})
+	cl.ApplyForce(Vector{1, 0})
+	cl.Tag = "clone"
+
+	if c.Pos != (Vector{1, 2}) || c.Acc != (Vector{0, 0}) || c.Tag == "clone" {
+		t.Error("WRONG !!")
+	}
+
+	c.Color = color.RGBA{0, 128, 255, 255}
+
+	if c.Clone().Color != (color.RGBA{0, 128, 255, 255}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_ClosestSurfacePoint(t *testing.T) {
+	c := Corpus{Pos: Vector{1, 1}, Mass: 1, Radius: 5}
+
+	if c.ClosestSurfacePoint(Vector{7, 9}) != (Vector{4, 5}) {
+		t.Error("WRONG !!")
+	}
+
+	// Inside points project outward too.
+	if c.ClosestSurfacePoint(Vector{1, 0}) != (Vector{1, -4}) {
+		t.Error("WRONG !!")
+	}
+
+	if c.ClosestSurfacePoint(c.Pos) != (Vector{6, 1}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Collide(t *testing.T) {
+	c := Corpus{Pos: Vector{0, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 1}
+	corpi := []Corpus{{Pos: Vector{1.5, 0}, Vel: Vector{-1, 0}, Mass: 1, Radius: 1}}
+
+	c.Collide(corpi)
+
+	if c.Vel != (Vector{-1, 0}) || corpi[0].Vel != (Vector{1, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	if c.Pos.Dist(corpi[0].Pos) != 2 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Collide_Self(t *testing.T) {
+	corpi := []Corpus{
+		{Pos: Vector{0, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 1},
+		{Pos: Vector{5, 0}, Vel: Vector{0, 1}, Mass: 1, Radius: 1},
+	}
+
+	for idx := range corpi {
+		corpi[idx].Collide(corpi)
+	}
+
+	for _, c := range corpi {
+		if !c.Pos.IsValid() || !c.Vel.IsValid() {
+			t.Error("WRONG !!")
+		}
+	}
+
+	if corpi[0].Pos != (Vector{0, 0}) || corpi[0].Vel != (Vector{1, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_CollideSwept(t *testing.T) {
+	// Without sweeping, a bullet faster than its diameter tunnels through a thin wall.
+	bullet := Corpus{Pos: Vector{0, 0}, Vel: Vector{10, 0}, Mass: 1, Radius: 0.5}
+	wall := Corpus{Pos: Vector{5, 0}, Radius: 0.1, Static: true}
+	bullet.CollideWith(&wall, 1)
+	bullet.Update()
+	bullet.CollideWith(&wall, 1)
+
+	if bullet.Vel.X < 0 {
+		t.Error("WRONG !!")
+	}
+
+	bullet = Corpus{Pos: Vector{0, 0}, Vel: Vector{10, 0}, Mass: 1, Radius: 0.5}
+
+	if !bullet.CollideSwept(&wall, 1, 1) {
+		t.Error("WRONG !!")
+	}
+	bullet.Update()
+
+	// Hits the wall at x = 4.4 and travels back the remaining 5.6.
+	if !bullet.Pos.Equals(Vector{-1.2, 0}, Epsilon) || !bullet.Vel.Equals(Vector{-10, 0}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	if wall.Pos != (Vector{5, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	far := Corpus{Pos: Vector{0, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 0.5}
+
+	if far.CollideSwept(&wall, 1, 1) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_CollideWith(t *testing.T) {
+	// Perfectly inelastic: equal-mass head-on corpi stick together.
+	c := Corpus{Pos: Vector{0, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 1}
+	cp := Corpus{Pos: Vector{1.5, 0}, Vel: Vector{-1, 0}, Mass: 1, Radius: 1}
+	c.CollideWith(&cp, 0)
+
+	if c.Vel != cp.Vel {
+		t.Error("WRONG !!")
+	}
+
+	// Perfectly elastic: velocities swap.
+	c = Corpus{Pos: Vector{0, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 1}
+	cp = Corpus{Pos: Vector{1.5, 0}, Vel: Vector{-1, 0}, Mass: 1, Radius: 1}
+	c.CollideWith(&cp, 1)
+
+	if c.Vel != (Vector{-1, 0}) || cp.Vel != (Vector{1, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	// Separating corpi keep their velocities.
+	c = Corpus{Pos: Vector{0, 0}, Vel: Vector{-1, 0}, Mass: 1, Radius: 1}
+	cp = Corpus{Pos: Vector{1.5, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 1}
+	c.CollideWith(&cp, 1)
+
+	if c.Vel != (Vector{-1, 0}) || cp.Vel != (Vector{1, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_CollideWith_Static(t *testing.T) {
+	c := Corpus{Pos: Vector{0, 0}, Vel: Vector{2, 0}, Mass: 1, Radius: 1}
+	wall := Corpus{Pos: Vector{1.5, 0}, Radius: 1, Static: true}
+	c.CollideWith(&wall, 1)
+
+	if wall.Pos != (Vector{1.5, 0}) || wall.Vel != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	if c.Vel != (Vector{-2, 0}) || c.Pos != (Vector{-0.5, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	// The static corpus is also the receiver in the other order.
+	c = Corpus{Pos: Vector{0, 0}, Vel: Vector{2, 0}, Mass: 1, Radius: 1}
+	wall.CollideWith(&c, 0.5)
+
+	if wall.Pos != (Vector{1.5, 0}) || c.Vel != (Vector{-1, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	wall.ApplyForce(Vector{1, 1})
+	wall.Update()
+
+	if wall.Pos != (Vector{1.5, 0}) || wall.Acc != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_CollideWith_Pinned(t *testing.T) {
+	a := Corpus{Pos: Vector{0, 0}, Vel: Vector{1.5, 0.25}, Mass: 2, Radius: 1}
+	b := Corpus{Pos: Vector{1.3, 0.7}, Vel: Vector{-0.5, -1}, Mass: 3, Radius: 0.75}
+	p := a.Momentum().Add(b.Momentum())
+
+	a.CollideWith(&b, 0.8)
+
+	// Bit for bit the outputs before the separation was computed once.
+	if a.Pos != (Vector{-0.14449465491828403, -0.07780481418676832}) || a.Vel != (Vector{-0.7380275229357802, -0.9550917431192665}) {
+		t.Error("WRONG !!")
+	}
+
+	if b.Pos != (Vector{1.3963297699455226, 0.7518698761245122}) || b.Vel != (Vector{0.992018348623853, -0.1966055045871561}) {
+		t.Error("WRONG !!")
+	}
+
+	if !a.Momentum().Add(b.Momentum()).Equals(p, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func BenchmarkCorpus_CollideWith(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		c := Corpus{Pos: Vector{0, 0}, Vel: Vector{1.5, 0.25}, Mass: 2, Radius: 1}
+		cp := Corpus{Pos: Vector{1.3, 0.7}, Vel: Vector{-0.5, -1}, Mass: 3, Radius: 0.75}
+		c.CollideWith(&cp, 0.8)
+	}
+}
+
+func TestResolveCollisions(t *testing.T) {
+	corpi := []Corpus{
+		{Pos: Vector{0, 0}, Vel: Vector{1, 0.5}, Mass: 1, Radius: 1},
+		{Pos: Vector{1.5, 0}, Vel: Vector{-1, 0}, Mass: 2, Radius: 1},
+		{Pos: Vector{0.75, 1.2}, Vel: Vector{0, -2}, Mass: 3, Radius: 1},
+	}
+	p := Vector{0, 0}
+	for _, c := range corpi {
+		p.AddP(c.Momentum())
+	}
+
+	ResolveCollisions(corpi, 1)
+
+	res := Vector{0, 0}
+	for _, c := range corpi {
+		res.AddP(c.Momentum())
+	}
+
+	if !res.Equals(p, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	if corpi[0].Vel == (Vector{1, 0.5}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestSolveDistance(t *testing.T) {
+	a := Corpus{Pos: Vector{0, 0}, Mass: 1}
+	b := Corpus{Pos: Vector{3, 0}, Mass: 3}
+	SolveDistance(&a, &b, 2)
+
+	if a.Pos.Dist(b.Pos) != 2 {
+		t.Error("WRONG !!")
+	}
+
+	if a.Pos != (Vector{0.75, 0}) || b.Pos != (Vector{2.75, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Contains(t *testing.T) {
+	c := Corpus{Pos: Vector{1, 1}, Mass: 1, Radius: 5}
+
+	if !c.Contains(Vector{4, 5}) || !c.Contains(Vector{1, 1}) {
+		t.Error("WRONG !!")
+	}
+
+	if c.Contains(Vector{4, 5.001}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_ContainsSq(t *testing.T) {
+	c := Corpus{Pos: Vector{1, 1}, Mass: 1, Radius: 5}
+
+	if !c.ContainsSq(Vector{4, 5}) || !c.ContainsSq(Vector{1, 1}) {
+		t.Error("WRONG !!")
+	}
+
+	if c.ContainsSq(Vector{4, 5.001}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Coulomb(t *testing.T) {
+	corpi := []Corpus{
+		{Pos: Vector{0, 0}, Mass: 1, Charge: 1},
+		{Pos: Vector{2, 0}, Mass: 1, Charge: 1},
+	}
+	corpi[0].Coulomb(corpi, 3)
+
+	// F = 3*1*1/2^2, like charges repel. The receiver is skipped.
+	if corpi[0].Force != (Vector{-0.75, 0}) || corpi[1].Force != (Vector{0.75, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCoulombAll(t *testing.T) {
+	corpi := []Corpus{
+		{Pos: Vector{0, 0}, Mass: 1, Charge: 1},
+		{Pos: Vector{2, 0}, Mass: 1, Charge: -1},
+	}
+	CoulombAll(corpi, 1)
+
+	// F = 1/2^2, not doubled, opposite charges attract.
+	if corpi[0].Force != (Vector{0.25, 0}) || corpi[1].Force != (Vector{-0.25, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Fragment(t *testing.T) {
+	c := Corpus{Pos: Vector{3, 4}, Vel: Vector{1, -2}, Mass: 6, Charge: 3, Radius: 2}
+	frags := c.Fragment(4, 5, rand.New(rand.NewSource(1)))
+
+	if len(frags) != 4 {
+		t.Error("WRONG !!")
+	}
+
+	var mass, charge, area float64
+	var momentum, moment Vector
+	for _, f := range frags {
+		mass += f.Mass
+		charge += f.Charge
+		area += f.Radius * f.Radius
+		momentum.AddP(f.Momentum())
+		moment.AddP(f.Pos.Mult(f.Mass))
+	}
+
+	if math.Abs(mass-6) > Epsilon || math.Abs(charge-3) > Epsilon || math.Abs(area-4) > Epsilon {
+		t.Error("WRONG !!")
+	}
+
+	if !momentum.Equals(c.Momentum(), Epsilon) || !moment.Div(mass).Equals(c.Pos, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	// Flying apart.
+	if frags[0].Vel == frags[1].Vel {
+		t.Error("WRONG !!")
+	}
+
+	// Every fragment lies within the corpus.
+	for seed := int64(1); seed <= 20; seed++ {
+		for _, f := range c.Fragment(3, 5, rand.New(rand.NewSource(seed))) {
+			if f.Pos.Dist(c.Pos)+f.Radius > c.Radius+Epsilon {
+				t.Error("WRONG !!")
+			}
+		}
+	}
+
+	if c.Fragment(0, 5, rand.New(rand.NewSource(1))) != nil {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Gravitate(t *testing.T) {
+	c := Corpus{Pos: Vector{0, 0}, Mass: 2}
+	corpi := []Corpus{{Pos: Vector{2, 0}, Mass: 4}}
+	c.Gravitate(corpi, 1)
+
+	// F = 1*2*4/2^2 = 2
+	if c.Force != (Vector{2, 0}) || corpi[0].Force != (Vector{-2, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	// The acceleration is left alone until the force is applied.
+	c.ApplyNetForce()
+
+	if c.Acc != (Vector{1, 0}) || !c.Force.IsZero() || !corpi[0].Acc.IsZero() {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestGravitateAll(t *testing.T) {
+	corpi := []Corpus{
+		{Pos: Vector{0, 0}, Mass: 1},
+		{Pos: Vector{2, 0}, Mass: 1},
+		{Pos: Vector{2, 2}, Mass: 1},
+	}
+	GravitateAll(corpi, 4, 0)
+
+	if !corpi[1].Force.Equals(Vector{-1, 1}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	sum := corpi[0].Force.Add(corpi[1].Force).Add(corpi[2].Force)
+
+	if !sum.Equals(Vector{0, 0}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_GravitateSoftened(t *testing.T) {
+	for _, dist := range []float64{1, 1e-3, 1e-9, 0} {
+		c := Corpus{Pos: Vector{0, 0}, Mass: 1}
+		corpi := []Corpus{{Pos: Vector{dist, 0}, Mass: 1}}
+		c.GravitateSoftened(corpi, 1, 0.1)
+
+		if !c.Force.IsValid() || c.Force.Mag() > 1/(0.1*0.1) {
+			t.Error("WRONG !!")
+		}
+	}
+}
+
+func TestCorpus_GravitateToward(t *testing.T) {
+	c := Corpus{Pos: Vector{3, 4}, Mass: 2, Radius: 1}
+	c.GravitateToward(Vector{0, 0}, 10)
+
+	// a = mu/r^2 = 10/25 toward the center
+	if !c.Acc.Equals(Vector{-0.24, -0.32}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	c = Corpus{Pos: Vector{0.001, 0}, Mass: 2, Radius: 1}
+	c.GravitateToward(Vector{0, 0}, 10)
+
+	if !c.Acc.IsValid() || c.Acc.X >= 0 || c.Acc.Mag() > 10 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_KineticEnergy(t *testing.T) {
+	c := Corpus{Vel: Vector{3, 0}, Mass: 2, Radius: 1}
+
+	if c.KineticEnergy() != 9 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := Corpus{Pos: Vector{0, 0}, Vel: Vector{2, 0}, Mass: 1, Charge: 1, Radius: 3, Tag: "a"}
+	b := Corpus{Pos: Vector{4, 0}, Vel: Vector{0, -1}, Mass: 3, Charge: -2, Radius: 4, Tag: "b"}
+	m := Merge(a, b)
+
+	if m.Mass != 4 || m.Charge != -1 || m.Radius != 5 || m.Tag != "b" {
+		t.Error("WRONG !!")
+	}
+
+	if !m.Pos.Equals(Vector{3, 0}, Epsilon) || !m.Momentum().Equals(a.Momentum().Add(b.Momentum()), Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	// Merging is symmetric.
+	if Merge(b, a) != m {
+		t.Error("WRONG !!")
+	}
+
+	// Collision filtering, immateriality, speed limit and life come from
+	// the heavier corpus.
+	a.Layer, a.Mask = 1, 1
+	b.Layer, b.Mask, b.Immaterial = 2, 6, true
+	b.SetMaxSpeed(10)
+	b.SetLife(3)
+	m = Merge(a, b)
+
+	if m.Layer != 2 || m.Mask != 6 || !m.Immaterial || m.MaxSpeed() != 10 || m.Life != 3 || !m.mortal {
+		t.Error("WRONG !!")
+	}
+
+	m.SetLife(-1)
+	m.Life = 0
+
+	if Merge(m, a).IsDead() {
+		t.Error("WRONG !!")
+	}
+
+	// A static corpus stays put.
+	b.Static = true
+	m = Merge(a, b)
+
+	if !m.Static || m.Pos != b.Pos || !m.Vel.IsZero() {
+		t.Error("WRONG !!")
+	}
+
+	m = Merge(Corpus{Pos: Vector{0, 0}}, Corpus{Pos: Vector{2, 2}})
+
+	if m.Pos != (Vector{1, 1}) || m.Mass != 0 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Momentum(t *testing.T) {
+	c := Corpus{Vel: Vector{3, 0}, Mass: 2, Radius: 1}
+
+	if c.Momentum() != (Vector{6, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	// Collide conserves total momentum and energy.
+	a := Corpus{Pos: Vector{0, 0}, Vel: Vector{2, 1}, Mass: 3, Radius: 1}
+	b := Corpus{Pos: Vector{1.5, 0.5}, Vel: Vector{-1, 0}, Mass: 1, Radius: 1}
+	p := a.Momentum().Add(b.Momentum())
+	e := a.KineticEnergy() + b.KineticEnergy()
+	a.CollideWith(&b, 1)
+
+	if !a.Momentum().Add(b.Momentum()).Equals(p, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	if math.Abs(a.KineticEnergy()+b.KineticEnergy()-e) > Epsilon {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Overlap(t *testing.T) {
+	c := Corpus{Pos: Vector{0, 0}, Mass: 1, Radius: 1}
+	cp := Corpus{Pos: Vector{1.5, 0}, Mass: 1, Radius: 1}
+
+	if c.Overlap(cp) != 0.5 || cp.Overlap(c) != 0.5 {
+		t.Error("WRONG !!")
+	}
+
+	cp.Pos = Vector{3, 0}
+
+	if c.Overlap(cp) != 0 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_SetLife(t *testing.T) {
+	c := NewCorpus()
+
+	// Immortal by default, even with no life.
+	if c.IsDead() {
+		t.Error("WRONG !!")
+	}
+
+	c.SetLife(1)
+	for i := 0; i < 3; i++ {
+		c.UpdateDt(0.25)
+	}
+
+	if c.IsDead() || math.Abs(c.Life-0.25) > Epsilon {
+		t.Error("WRONG !!")
+	}
+
+	c.UpdateDt(0.25)
+
+	if !c.IsDead() {
+		t.Error("WRONG !!")
+	}
+
+	// Immaterial particles age too.
+	p := Corpus{Immaterial: true}
+	p.SetLife(0.5)
+	p.UpdateVerlet(0.5)
+
+	if !p.IsDead() {
+		t.Error("WRONG !!")
+	}
+
+	p.SetLife(-1)
+
+	if p.IsDead() {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_SetMaxSpeed(t *testing.T) {
+	c := NewCorpus(WithMaxSpeed(5))
+	c.ApplyForce(Vector{30, 40})
+	c.Update()
+
+	if c.MaxSpeed() != 5 || !c.Vel.Equals(Vector{3, 4}, Epsilon) || !c.Pos.Equals(Vector{3, 4}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	// Below the cap nothing changes.
+	c.Vel = Vector{1, 0}
+	c.UpdateDt(1)
+
+	if c.Vel != (Vector{1, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	c.SetMaxSpeed(0)
+	c.ApplyForce(Vector{100, 0})
+	c.UpdateVerlet(1)
+
+	if c.MaxSpeed() != 0 || c.Vel != (Vector{101, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	c.SetMaxSpeed(2)
+	c.UpdateRK4(1, func(pos, vel Vector) Vector { return Vector{} })
+
+	if math.Abs(c.Vel.Mag()-2) > Epsilon {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_String(t *testing.T) {
+	c := Corpus{Pos: Vector{1, 2}, Vel: Vector{-3, 0.5}, Mass: 2, Radius: 1.25}
+	res := "Corpus{pos: (1.000, 2.000), vel: (-3.000, 0.500), mass: 2.000, radius: 1.250}"
+
+	if c.String() != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_TimeOfImpact(t *testing.T) {
+	c := Corpus{Pos: Vector{0, 0}, Vel: Vector{2, 0}, Mass: 1, Radius: 1}
+	cp := Corpus{Pos: Vector{10, 0}, Vel: Vector{-2, 0}, Mass: 1, Radius: 1}
+
+	if toi, ok := c.TimeOfImpact(&cp, 5); !ok || toi != 2 {
+		t.Error("WRONG !!")
+	}
+
+	if _, ok := c.TimeOfImpact(&cp, 1); ok {
+		t.Error("WRONG !!")
+	}
+
+	cp.Vel = Vector{3, 0}
+
+	if _, ok := c.TimeOfImpact(&cp, 100); ok {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_Update(t *testing.T) {
+	pos := Vector{0, 0}
+	vel := Vector{1, 1}
+	acc := Vector{0, 0}
+
+	mass := 1.0
+	radius := 1.0
+
+	c := Corpus{Pos: pos, Vel: vel, Acc: acc, Mass: mass, Radius: radius}
+
+	for i := 0; i < 10; i++ {
+		c.Update()
+	}
+
+	resPos := Vector{10, 10}
+	resVel := Vector{1, 1}
+
+	if c.Pos != resPos {
+		t.Error("WRONG POS!!")
+	}
+
+	if c.Vel != resVel {
+		t.Error("WRONG VEL")
+	}
+
+}
+
+func TestCorpus_UpdateDt(t *testing.T) {
+	a := Corpus{Vel: Vector{2, 4}, Mass: 1, Radius: 1}
+	b := a
+	a.UpdateDt(1)
+	b.UpdateDt(0.5)
+
+	if b.Pos != a.Pos.Div(2) {
+		t.Error("WRONG !!")
+	}
+
+	// Semi-implicit: velocity is updated before position.
+	c := Corpus{Mass: 1, Radius: 1}
+	c.ApplyForce(Vector{4, 0})
+	c.UpdateDt(0.5)
+
+	if c.Vel != (Vector{2, 0}) || c.Pos != (Vector{1, 0}) || c.Acc != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_UpdateRK4(t *testing.T) {
+	// Harmonic oscillator F = -k*x with k = 4, m = 1 has period pi.
+	spring := func(pos, vel Vector) Vector {
+		return pos.Mult(-4)
+	}
+	c := Corpus{Pos: Vector{1, 0}, Mass: 1, Radius: 1}
+	steps := 1000
+	for i := 0; i < steps; i++ {
+		c.UpdateRK4(math.Pi/float64(steps), spring)
+	}
+
+	if !c.Pos.Equals(Vector{1, 0}, 1e-6) || !c.Vel.Equals(Vector{0, 0}, 1e-6) {
+		t.Error("WRONG !!")
+	}
+
+	// A quarter period in, x = cos(2t) = 0 and v = -2*sin(2t) = -2.
+	c = Corpus{Pos: Vector{1, 0}, Mass: 1, Radius: 1}
+	for i := 0; i < steps/4; i++ {
+		c.UpdateRK4(math.Pi/float64(steps), spring)
+	}
+
+	if !c.Pos.Equals(Vector{0, 0}, 1e-6) || !c.Vel.Equals(Vector{-2, 0}, 1e-6) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_UpdateVerlet(t *testing.T) {
+	// A circular orbit of radius 10 around a fixed sun, v = sqrt(mu/r).
+	orbit := func(update func(c *Corpus, dt float64)) float64 {
+		c := Corpus{Pos: Vector{10, 0}, Vel: Vector{0, math.Sqrt(10)}, Mass: 1, Radius: 0.1}
+		drift := 0.0
+		for i := 0; i < 2000; i++ {
+			c.GravitateToward(Vector{0, 0}, 100)
+			update(&c, 0.1)
+			drift = math.Max(drift, math.Abs(c.Pos.Mag()-10))
+		}
+		return drift
+	}
+
+	verlet := orbit((*Corpus).UpdateVerlet)
+	euler := orbit((*Corpus).UpdateDt)
+
+	if verlet > 0.01 || verlet > euler/10 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestClamp(t *testing.T) {
+	if Clamp(5, 0, 3) != 3 || Clamp(-1, 0, 3) != 0 || Clamp(2, 0, 3) != 2 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestDegToRad(t *testing.T) {
+	if math.Abs(DegToRad(180)-math.Pi) > Epsilon || DegToRad(0) != 0 {
+		t.Error("WRONG !!")
+	}
+
+	for _, d := range []float64{-720, -45, 0, 30, 90, 359.5} {
+		if math.Abs(RadToDeg(DegToRad(d))-d) > Epsilon {
+			t.Error("WRONG !!")
+		}
+	}
+}
+
+func TestLerp(t *testing.T) {
+	if Lerp(0, 10, 0.5) != 5 || Lerp(0, 10, 2) != 20 || Lerp(3, 3, 0.7) != 3 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestRadToDeg(t *testing.T) {
+	if math.Abs(RadToDeg(math.Pi/2)-90) > Epsilon {
+		t.Error("WRONG !!")
+	}
+
+	for _, r := range []float64{-10, -math.Pi, 0, 1, 2 * math.Pi} {
+		if math.Abs(DegToRad(RadToDeg(r))-r) > Epsilon {
+			t.Error("WRONG !!")
+		}
+	}
+}
+
+func TestWrapAngle(t *testing.T) {
+	if math.Abs(WrapAngle(3*math.Pi)-math.Pi) > Epsilon || WrapAngle(-math.Pi) != math.Pi {
+		t.Error("WRONG !!")
+	}
+
+	if WrapAngle(1) != 1 || math.Abs(WrapAngle(-7)-(2*math.Pi-7)) > Epsilon || math.Abs(WrapAngle(7)-(7-2*math.Pi)) > Epsilon {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestAddSlice(t *testing.T) {
+	a := []Vector{{1, 2}, {3, 4}}
+	b := []Vector{{-1, 1}, {0.5, 0}}
+	dst := make([]Vector, 2)
+
+	AddSlice(dst, a, b)
+
+	if dst[0] != (Vector{0, 3}) || dst[1] != (Vector{3.5, 4}) {
+		t.Error("WRONG !!")
+	}
+
+	// In place.
+	AddSlice(a, a, b)
+
+	if a[0] != dst[0] || a[1] != dst[1] {
+		t.Error("WRONG !!")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("WRONG !!")
+		}
+	}()
+	AddSlice(dst, a, b[:1])
+}
+
+func BenchmarkAddSlice(b *testing.B) {
+	a, c := make([]Vector, 10000), make([]Vector, 10000)
+	dst := make([]Vector, 10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		AddSlice(dst, a, c)
+	}
+}
+
+func TestFromComplex(t *testing.T) {
+	a := Vector{3, 4}
+
+	if FromComplex(a.Complex()) != a {
+		t.Error("WRONG !!")
+	}
+
+	// Multiplying by i rotates by 90 degrees.
+	if FromComplex(a.Complex()*1i) != a.Perp() {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestFromPolar(t *testing.T) {
+	if FromPolar(5, 0) != (Vector{5, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	a := FromPolar(1, math.Pi/2)
+
+	if !a.Equals(Vector{0, 1}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	b := Vector{-3, 4}
+	c := FromPolar(b.Mag(), b.Angle())
+
+	if !c.Equals(b, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestFromSlice(t *testing.T) {
+	a, err := FromSlice([]float64{3, 4})
+
+	if err != nil || a != (Vector{3, 4}) {
+		t.Error("WRONG !!")
+	}
+
+	if _, err := FromSlice([]float64{1, 2, 3}); err == nil {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	if Orientation(Vector{0, 0}, Vector{1, 1}, Vector{2, 2}) != 0 {
+		t.Error("WRONG !!")
+	}
+
+	if Orientation(Vector{0, 0}, Vector{1, 0}, Vector{1, 1}) != 1 {
+		t.Error("WRONG !!")
+	}
+
+	if Orientation(Vector{0, 0}, Vector{1, 0}, Vector{1, -1}) != -1 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestRandUnit(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	sum := Vector{0, 0}
+
+	for i := 0; i < 1000; i++ {
+		a := RandUnit(r)
+		if math.Abs(a.Mag()-1) > Epsilon {
+			t.Error("WRONG !!")
+		}
+		sum.AddP(a)
+	}
+
+	// Uniform directions should roughly cancel out.
+	if sum.Div(1000).Mag() > 0.1 {
+		t.Error("WRONG !!")
+	}
+
+	if RandUnit(rand.New(rand.NewSource(7))) != RandUnit(rand.New(rand.NewSource(7))) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestScaleSlice(t *testing.T) {
+	a := []Vector{{1, 2}, {-3, 4}}
+	dst := make([]Vector, 2)
+
+	ScaleSlice(dst, a, 2)
+
+	if dst[0] != (Vector{2, 4}) || dst[1] != (Vector{-6, 8}) {
+		t.Error("WRONG !!")
+	}
+
+	ScaleSlice(a, a, 2)
+
+	if a[0] != dst[0] || a[1] != dst[1] {
+		t.Error("WRONG !!")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("WRONG !!")
+		}
+	}()
+	ScaleSlice(dst[:1], a, 2)
+}
+
+func BenchmarkScaleSlice(b *testing.B) {
+	a, dst := make([]Vector, 10000), make([]Vector, 10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ScaleSlice(dst, a, 2)
+	}
+}
+
+func TestSegmentIntersect(t *testing.T) {
+	p, ok := SegmentIntersect(Vector{0, 0}, Vector{2, 2}, Vector{0, 2}, Vector{2, 0})
+
+	if !ok || !p.Equals(Vector{1, 1}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	// Would cross if extended, but the segments stop short.
+	if _, ok := SegmentIntersect(Vector{0, 0}, Vector{1, 1}, Vector{3, 0}, Vector{2, 1}); ok {
+		t.Error("WRONG !!")
+	}
+
+	if _, ok := SegmentIntersect(Vector{0, 0}, Vector{2, 0}, Vector{0, 1}, Vector{2, 1}); ok {
+		t.Error("WRONG !!")
+	}
+
+	if _, ok := SegmentIntersect(Vector{0, 0}, Vector{2, 0}, Vector{1, 0}, Vector{3, 0}); ok {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Abs(t *testing.T) {
+	a := Vector{-3, 4}
+	res := Vector{3, 4}
+
+	if a.Abs() != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Add(t *testing.T) {
+	a := Vector{1, 1}
+	b := Vector{2, 2}
+
+	res := Vector{3, 3}
+
+	if a.Add(b) != res {
+		t.Error("WRONG!!")
+	}
+
+}
+
+func TestVector_AddP(t *testing.T) {
+	a := Vector{1, 1}
+	b := Vector{2, 2}
+	a.AddP(b)
+	res := Vector{3, 3}
+
+	if a != res {
+		t.Error("WRONG!!")
+	}
+
+}
+
+func TestVector_Angle(t *testing.T) {
+	if (Vector{1, 0}).Angle() != 0 {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector{0, 1}).Angle() != math.Pi/2 {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector{-1, 0}).Angle() != math.Pi {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector{-1, math.Copysign(0, -1)}).Angle() != math.Pi {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector{0, -1}).Angle() != -math.Pi/2 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_AngleBetween(t *testing.T) {
+	a := Vector{1, 0}
+	b := Vector{0, 1}
+
+	res := math.Pi / 2
+
+	if math.Abs(a.AngleBetween(b)-res) > 0.001 {
+		t.Error("WRONG!!")
+	}
+
+	if a.AngleBetween(a) != 0 {
+		t.Error("WRONG!!")
+	}
+
+	if a.AngleBetween(Vector{-1, 0}) != math.Pi {
+		t.Error("WRONG!!")
+	}
+
+	c := Vector{0.1, 0.7}
+
+	if math.IsNaN(c.AngleBetween(c)) || math.IsNaN(c.AngleBetween(c.Mult(-3))) {
+		t.Error("WRONG!!")
+	}
+}
+
+func TestVector_Ceil(t *testing.T) {
+	a := Vector{-0.5, 0.5}
+	res := Vector{0, 1}
+
+	if a.Ceil() != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_ClampRect(t *testing.T) {
+	a := Vector{15, -3}
+	res := Vector{10, 0}
+
+	if a.ClampRect(Vector{0, 0}, Vector{10, 10}) != res {
+		t.Error("WRONG !!")
+	}
+
+	if res.ClampRect(Vector{0, 0}, Vector{10, 10}) != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Complex(t *testing.T) {
+	a := Vector{3, 4}
+
+	if a.Complex() != 3+4i {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Cross(t *testing.T) {
+	a := Vector{1, 0}
+	b := Vector{0, 1}
+
+	if a.Cross(b) != 1 || b.Cross(a) != -1 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_DirectionTo(t *testing.T) {
+	a := Vector{0, 0}
+	b := Vector{0, 5}
+	res := Vector{0, 1}
+
+	if a.DirectionTo(b) != res {
+		t.Error("WRONG !!")
+	}
+
+	if a.DirectionTo(a) != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Dist(t *testing.T) {
+	a := Vector{1, 0}
+	b := Vector{0, 1}
+
+	res := math.Sqrt(2)
+
+	if a.Dist(b)-res > 0.001 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_DistChebyshev(t *testing.T) {
+	a := Vector{0, 0}
+	b := Vector{3, 4}
+
+	if a.DistChebyshev(b) != 4 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_DistManhattan(t *testing.T) {
+	a := Vector{0, 0}
+	b := Vector{3, 4}
+
+	if a.DistManhattan(b) != 7 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_DistSq(t *testing.T) {
+	a := Vector{1, 0}
+	b := Vector{0, 1}
+
+	res := 2.0
+
+	if a.DistSq(b) != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_DistToSegment(t *testing.T) {
+	a := Vector{-1, 0}
+	b := Vector{1, 0}
+
+	if (Vector{0, 1}).DistToSegment(a, b) != 1 {
+		t.Error("WRONG !!")
+	}
+
+	// Beyond the end of the segment the distance is to the endpoint.
+	if (Vector{4, 4}).DistToSegment(a, b) != 5 {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector{3, 4}).DistToSegment(Vector{0, 0}, Vector{0, 0}) != 5 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Div(t *testing.T) {
+	a := Vector{2, 2}
+	res := Vector{1, 1}
+
+	if a.Div(2) != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_DivP(t *testing.T) {
+	a := Vector{2, 2}
+	res := Vector{1, 1}
+
+	a.DivP(2)
+
+	if a != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Dot(t *testing.T) {
+	a := Vector{1, 2}
+	b := Vector{3, 4}
+	res := 11.0
+
+	if a.Dot(b) != res {
+		t.Error("WRONG")
 	}
+}
 
-	resPos := Vector{10, 10}
-	resVel := Vector{1, 1}
+func TestVector_Equals(t *testing.T) {
+	a := Vector{1, 1}
+	b := Vector{1 + 1e-12, 1}
 
-	if c.Pos != resPos {
-		t.Error("WRONG POS!!")
+	if !a.Equals(b, Epsilon) {
+		t.Error("WRONG !!")
 	}
 
-	if c.Vel != resVel {
-		t.Error("WRONG VEL")
+	if a.Equals(Vector{1.1, 1}, Epsilon) {
+		t.Error("WRONG !!")
 	}
-
 }
 
-func TestVector_Add(t *testing.T) {
-	a := Vector{1, 1}
-	b := Vector{2, 2}
-
-	res := Vector{3, 3}
+func TestVector_Floor(t *testing.T) {
+	a := Vector{-0.5, 0.5}
+	res := Vector{-1, 0}
 
-	if a.Add(b) != res {
-		t.Error("WRONG!!")
+	if a.Floor() != res {
+		t.Error("WRONG !!")
 	}
-
 }
 
-func TestVector_AddP(t *testing.T) {
-	a := Vector{1, 1}
-	b := Vector{2, 2}
-	a.AddP(b)
-	res := Vector{3, 3}
+func TestVector_IsValid(t *testing.T) {
+	if !(Vector{0, 0}).IsValid() {
+		t.Error("WRONG !!")
+	}
 
-	if a != res {
-		t.Error("WRONG!!")
+	if (Vector{math.NaN(), 0}).IsValid() {
+		t.Error("WRONG !!")
 	}
 
+	if (Vector{math.Inf(1), 1}).IsValid() {
+		t.Error("WRONG !!")
+	}
 }
 
-func TestVector_AngleBetween(t *testing.T) {
-	a := Vector{1, 0}
-	b := Vector{0, 1}
+func TestVector_IsZero(t *testing.T) {
+	if !(Vector{0, 0}).IsZero() {
+		t.Error("WRONG !!")
+	}
 
-	res := 2 * math.Pi
+	if (Vector{math.NaN(), 0}).IsZero() {
+		t.Error("WRONG !!")
+	}
 
-	if a.AngleBetween(b)-res > 0.001 {
-		t.Error("WRONG!!")
+	if (Vector{math.Inf(1), 1}).IsZero() {
+		t.Error("WRONG !!")
 	}
 }
 
-func TestVector_Dist(t *testing.T) {
-	a := Vector{1, 0}
-	b := Vector{0, 1}
-
-	res := math.Sqrt(2)
+func TestVector_Lerp(t *testing.T) {
+	a := Vector{0, 0}
+	b := Vector{10, 10}
 
-	if a.Dist(b)-res > 0.001 {
+	if a.Lerp(b, 0) != a {
 		t.Error("WRONG !!")
 	}
-}
 
-func TestVector_DistSq(t *testing.T) {
-	a := Vector{1, 0}
-	b := Vector{0, 1}
+	if a.Lerp(b, 0.5) != (Vector{5, 5}) {
+		t.Error("WRONG !!")
+	}
 
-	res := 2.0
+	if a.Lerp(b, 1) != b {
+		t.Error("WRONG !!")
+	}
 
-	if a.DistSq(b) != res {
+	if a.Lerp(b, 2) != (Vector{20, 20}) {
 		t.Error("WRONG !!")
 	}
 }
 
-func TestVector_Div(t *testing.T) {
-	a := Vector{2, 2}
-	res := Vector{1, 1}
+func TestVector_LerpClamped(t *testing.T) {
+	a := Vector{0, 0}
+	b := Vector{10, 10}
 
-	if a.Div(2) != res {
+	if a.LerpClamped(b, 0.5) != (Vector{5, 5}) {
+		t.Error("WRONG !!")
+	}
+
+	if a.LerpClamped(b, 2) != b {
+		t.Error("WRONG !!")
+	}
+
+	if a.LerpClamped(b, -1) != a {
 		t.Error("WRONG !!")
 	}
 }
 
-func TestVector_DivP(t *testing.T) {
-	a := Vector{2, 2}
-	res := Vector{1, 1}
+func TestVector_Limit(t *testing.T) {
+	a := Vector{3, 4}
 
-	a.DivP(2)
+	if math.Abs(a.Limit(2.5).Mag()-2.5) > 0.001 {
+		t.Error("WRONG !!")
+	}
 
-	if a != res {
+	if a.Limit(10) != a {
+		t.Error("WRONG !!")
+	}
+
+	if a.Limit(-2) != (Vector{0, 0}) {
 		t.Error("WRONG !!")
 	}
 }
 
-func TestVector_Dot(t *testing.T) {
-	a := Vector{1, 2}
-	b := Vector{3, 4}
-	res := 11.0
+func TestVector_LimitP(t *testing.T) {
+	a := Vector{3, 4}
+	a.LimitP(2.5)
 
-	if a.Dot(b) != res {
-		t.Error("WRONG")
+	if math.Abs(a.Mag()-2.5) > 0.001 {
+		t.Error("WRONG !!")
+	}
+
+	a.LimitP(-2)
+
+	if a != (Vector{0, 0}) {
+		t.Error("WRONG !!")
 	}
 }
 
@@ -186,6 +1716,49 @@ func TestVector_MagSq(t *testing.T) {
 	}
 }
 
+func TestVector_Max(t *testing.T) {
+	a := Vector{1, 5}
+	b := Vector{3, 2}
+	res := Vector{3, 5}
+
+	if a.Max(b) != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Midpoint(t *testing.T) {
+	a := Vector{0, 0}
+	b := Vector{4, 6}
+	res := Vector{2, 3}
+
+	if a.Midpoint(b) != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Min(t *testing.T) {
+	a := Vector{1, 5}
+	b := Vector{3, 2}
+	res := Vector{1, 2}
+
+	if a.Min(b) != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_MoveTowards(t *testing.T) {
+	a := Vector{0, 0}
+	b := Vector{10, 0}
+
+	if a.MoveTowards(b, 3) != (Vector{3, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	if a.MoveTowards(b, 100) != b {
+		t.Error("WRONG !!")
+	}
+}
+
 func TestVector_Mult(t *testing.T) {
 	a := Vector{3, 4}
 	b := 3.0
@@ -209,11 +1782,24 @@ func TestVector_MultP(t *testing.T) {
 	}
 }
 
+func TestVector_Neg(t *testing.T) {
+	a := Vector{3, -4}
+	res := Vector{-3, 4}
+
+	if a.Neg() != res {
+		t.Error("WRONG !!")
+	}
+}
+
 func TestVector_Norm(t *testing.T) {
 	a := Vector{10, 0}
 	res := Vector{1, 0}
 
-	if a.Norm() != res {
+	if !a.Norm().Equals(res, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector{0, 0}).Norm() != (Vector{0, 0}) {
 		t.Error("WRONG !!")
 	}
 }
@@ -224,7 +1810,153 @@ func TestVector_NormP(t *testing.T) {
 
 	a.NormP()
 
-	if a != res {
+	if !a.Equals(res, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	z := Vector{0, 0}
+	z.NormP()
+
+	if z != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Perp(t *testing.T) {
+	a := Vector{3, 4}
+	res := Vector{-4, 3}
+
+	if a.Perp() != res {
+		t.Error("WRONG !!")
+	}
+
+	for _, v := range []Vector{{1, 0}, {0, 1}, {3, 4}, {-2.5, 7}} {
+		if v.Perp().Dot(v) != 0 {
+			t.Error("WRONG !!")
+		}
+	}
+}
+
+func TestVector_PerpCW(t *testing.T) {
+	a := Vector{3, 4}
+	res := Vector{4, -3}
+
+	if a.PerpCW() != res {
+		t.Error("WRONG !!")
+	}
+
+	if a.PerpCW().Dot(a) != 0 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Project(t *testing.T) {
+	a := Vector{3, 4}
+	res := Vector{3, 0}
+
+	if a.Project(Vector{1, 0}) != res {
+		t.Error("WRONG !!")
+	}
+
+	if a.Project(Vector{0, 0}) != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Reflect(t *testing.T) {
+	a := Vector{1, -1}
+	res := Vector{1, 1}
+
+	if a.Reflect(Vector{0, 1}) != res {
+		t.Error("WRONG !!")
+	}
+
+	if a.Reflect(Vector{0, 5}) != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Reject(t *testing.T) {
+	a := Vector{3, 4}
+	onto := Vector{1, 0}
+	res := Vector{0, 4}
+
+	if a.Reject(onto) != res {
+		t.Error("WRONG !!")
+	}
+
+	if a.Project(onto).Add(a.Reject(onto)) != a {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Rotate(t *testing.T) {
+	a := Vector{1, 0}
+	res := Vector{0, 1}
+	rot := a.Rotate(math.Pi / 2)
+
+	if !rot.Equals(res, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	b := Vector{3, 4}
+	full := b.Rotate(2 * math.Pi)
+
+	if !full.Equals(b, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_RotateAround(t *testing.T) {
+	a := Vector{2, 0}
+	pivot := Vector{1, 0}
+	res := Vector{0, 0}
+	rot := a.RotateAround(pivot, math.Pi)
+
+	if !rot.Equals(res, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_RotateP(t *testing.T) {
+	a := Vector{1, 0}
+	res := Vector{0, 1}
+	a.RotateP(math.Pi / 2)
+
+	if !a.Equals(res, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Round(t *testing.T) {
+	a := Vector{-1.5, 1.4}
+	res := Vector{-2, 1}
+
+	if a.Round() != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Scale(t *testing.T) {
+	a := Vector{2, 3}
+	b := Vector{4, 5}
+	res := Vector{8, 15}
+
+	if a.Scale(b) != res {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_ScaleDiv(t *testing.T) {
+	a := Vector{8, 15}
+	b := Vector{4, 5}
+	res := Vector{2, 3}
+
+	if a.ScaleDiv(b) != res {
+		t.Error("WRONG !!")
+	}
+
+	if a.ScaleDiv(Vector{0, 5}) != (Vector{0, 3}) {
 		t.Error("WRONG !!")
 	}
 }
@@ -248,6 +1980,56 @@ func TestVector_SetMagP(t *testing.T) {
 	}
 }
 
+func TestVector_SignedAngle(t *testing.T) {
+	a := Vector{1, 0}
+
+	if a.SignedAngle(Vector{0, 1}) != math.Pi/2 {
+		t.Error("WRONG !!")
+	}
+
+	if a.SignedAngle(Vector{0, -1}) != -math.Pi/2 {
+		t.Error("WRONG !!")
+	}
+
+	if a.SignedAngle(Vector{-1, 0}) != math.Pi {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Slice(t *testing.T) {
+	a := Vector{3, 4}
+	s := a.Slice()
+
+	if len(s) != 2 || s[0] != 3 || s[1] != 4 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_Snap(t *testing.T) {
+	a := Vector{0.4, 1.6}
+
+	if a.Snap(1) != (Vector{0, 2}) {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector{7, -7}).Snap(5) != (Vector{5, -5}) {
+		t.Error("WRONG !!")
+	}
+
+	if a.Snap(0) != a {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector_String(t *testing.T) {
+	a := Vector{1, -2.5}
+	res := "(1.000, -2.500)"
+
+	if a.String() != res {
+		t.Error("WRONG !!")
+	}
+}
+
 func TestVector_Sub(t *testing.T) {
 	a := Vector{3, 4}
 	b := Vector{1, 2}
@@ -271,3 +2053,16 @@ func TestVector_SubP(t *testing.T) {
 		t.Error("WRONG !!")
 	}
 }
+
+func TestVector_WeightedMid(t *testing.T) {
+	a := Vector{0, 0}
+	b := Vector{4, 8}
+
+	if a.WeightedMid(b, 0.25) != (Vector{1, 2}) {
+		t.Error("WRONG !!")
+	}
+
+	if a.WeightedMid(b, 0.5) != a.Midpoint(b) {
+		t.Error("WRONG !!")
+	}
+}
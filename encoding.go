@@ -0,0 +1,341 @@
+package corpus
+
+import (
+    "bytes"
+    "encoding/gob"
+    "encoding/json"
+    "fmt"
+    "image/color"
+)
+
+// vectorJSON is the JSON representation of a Vector.
+type vectorJSON struct {
+    X float64 `json:"x"`
+    Y float64 `json:"y"`
+}
+
+// corpusJSON is the JSON representation of a Corpus.
+type corpusJSON struct {
+    Pos        Vector   `json:"pos"`
+    Vel        Vector   `json:"vel"`
+    Acc        *Vector  `json:"acc,omitempty"`
+    Force      *Vector  `json:"force,omitempty"`
+    Mass       float64  `json:"mass"`
+    Charge     float64  `json:"charge,omitempty"`
+    Radius     float64  `json:"radius"`
+    Immaterial bool     `json:"immaterial,omitempty"`
+    Static     bool     `json:"static,omitempty"`
+    Tag        string   `json:"tag,omitempty"`
+    Layer      uint32   `json:"layer,omitempty"`
+    Mask       uint32   `json:"mask,omitempty"`
+    MaxSpeed   float64  `json:"maxSpeed,omitempty"`
+    Life       *float64 `json:"life,omitempty"`
+    Asleep     bool     `json:"asleep,omitempty"`
+    Idle       int      `json:"idle,omitempty"`
+    PrevAcc    *Vector  `json:"prevAcc,omitempty"`
+    Color      string   `json:"color,omitempty"`
+}
+
+// worldJSON is the JSON representation of a World.
+type worldJSON struct {
+    Corpi          []Corpus        `json:"corpi"`
+    IDs            []ID            `json:"ids"`
+    LastID         ID              `json:"lastID"`
+    G              float64         `json:"g,omitempty"`
+    K              float64         `json:"k,omitempty"`
+    Softening      float64         `json:"softening,omitempty"`
+    Restitution    float64         `json:"restitution"`
+    Width          float64         `json:"width,omitempty"`
+    Height         float64         `json:"height,omitempty"`
+    Broadphase     *broadphaseJSON `json:"broadphase,omitempty"`
+    QueryMode      QueryMode       `json:"queryMode,omitempty"`
+    MergeOnContact bool            `json:"mergeOnContact,omitempty"`
+    SleepSpeed     float64         `json:"sleepSpeed,omitempty"`
+    SleepAcc       float64         `json:"sleepAcc,omitempty"`
+    SleepSteps     int             `json:"sleepSteps,omitempty"`
+    Workers        int             `json:"workers,omitempty"`
+    Accum          float64         `json:"accum,omitempty"`
+    Iterations     int             `json:"iterations,omitempty"`
+    FrameDelay     int             `json:"frameDelay,omitempty"`
+}
+
+// broadphaseJSON is the JSON representation of the broadphases of the
+// package, told apart by Type.
+type broadphaseJSON struct {
+    Type     string  `json:"type"`
+    CellSize float64 `json:"cellSize,omitempty"`
+    Capacity int     `json:"capacity,omitempty"`
+    MaxDepth int     `json:"maxDepth,omitempty"`
+}
+
+// MarshalJSON encodes the vector as {"x": x, "y": y}.
+func (a Vec[T]) MarshalJSON() ([]byte, error) {
+    return json.Marshal(vectorJSON{float64(a.X), float64(a.Y)})
+}
+
+// UnmarshalJSON decodes a vector encoded by MarshalJSON.
+func (a *Vec[T]) UnmarshalJSON(data []byte) error {
+    var v vectorJSON
+    if err := json.Unmarshal(data, &v); err != nil {
+        return err
+    }
+    a.X, a.Y = T(v.X), T(v.Y)
+    return nil
+}
+
+// MarshalJSON encodes the corpus with lowercase keys.
+// Acc, Force, Charge, Immaterial, Static, Tag, Layer, Mask and the maximum
+// speed are omitted when they are zero, and Life when it was never set.
+// Color is encoded as "#rrggbbaa", and omitted when it is the default white.
+// The sleep and UpdateVerlet state are kept, when there is any, so that a
+// decoded corpus carries on exactly as the original. UserData is not.
+func (c Corpus) MarshalJSON() ([]byte, error) {
+    v := corpusJSON{
+        Pos:        c.Pos,
+        Vel:        c.Vel,
+        Mass:       c.Mass,
+        Charge:     c.Charge,
+        Radius:     c.Radius,
+        Immaterial: c.Immaterial,
+        Static:     c.Static,
+        Tag:        c.Tag,
+        Layer:      c.Layer,
+        Mask:       c.Mask,
+        MaxSpeed:   c.maxSpeed,
+        Asleep:     c.asleep,
+        Idle:       c.idle,
+    }
+    if c.Acc != (Vector{0, 0}) {
+        v.Acc = &c.Acc
+    }
+    if c.Force != (Vector{0, 0}) {
+        v.Force = &c.Force
+    }
+    if c.mortal {
+        v.Life = &c.Life
+    }
+    if c.verlet {
+        v.PrevAcc = &c.prevAcc
+    }
+    if col := c.Color; col != white {
+        v.Color = fmt.Sprintf("#%02x%02x%02x%02x", col.R, col.G, col.B, col.A)
+    }
+    return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a corpus encoded by MarshalJSON.
+// Omitted fields are set to their zero values, but Color to white.
+func (c *Corpus) UnmarshalJSON(data []byte) error {
+    var v corpusJSON
+    if err := json.Unmarshal(data, &v); err != nil {
+        return err
+    }
+    *c = Corpus{
+        Pos:        v.Pos,
+        Vel:        v.Vel,
+        Mass:       v.Mass,
+        Charge:     v.Charge,
+        Radius:     v.Radius,
+        Immaterial: v.Immaterial,
+        Static:     v.Static,
+        Tag:        v.Tag,
+        Layer:      v.Layer,
+        Mask:       v.Mask,
+        maxSpeed:   v.MaxSpeed,
+        asleep:     v.Asleep,
+        idle:       v.Idle,
+    }
+    if v.Acc != nil {
+        c.Acc = *v.Acc
+    }
+    if v.Force != nil {
+        c.Force = *v.Force
+    }
+    if v.Life != nil {
+        c.Life, c.mortal = *v.Life, true
+    }
+    if v.PrevAcc != nil {
+        c.prevAcc, c.verlet = *v.PrevAcc, true
+    }
+    c.Color = white
+    if v.Color != "" {
+        col := &c.Color
+        if _, err := fmt.Sscanf(v.Color, "#%02x%02x%02x%02x", &col.R, &col.G, &col.B, &col.A); err != nil {
+            return fmt.Errorf("corpus: bad color %q: %v", v.Color, err)
+        }
+    }
+    return nil
+}
+
+// MarshalJSON encodes the world with its corpi, their IDs, its constants
+// and bounds, and its settings, so that a decoded world steps exactly as
+// the original. The force generators, collision callback and random
+// source cannot be encoded, and a broadphase other than a SpatialHash or
+// a Quadtree is an error.
+func (w *World) MarshalJSON() ([]byte, error) {
+    v, err := w.encode()
+    if err != nil {
+        return nil, err
+    }
+    v.Corpi = w.Corpi
+    return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a world encoded by MarshalJSON. The force
+// generators, collision callback and random source of the world are left
+// as they were.
+func (w *World) UnmarshalJSON(data []byte) error {
+    var v worldJSON
+    if err := json.Unmarshal(data, &v); err != nil {
+        return err
+    }
+    return w.decode(v, v.Corpi)
+}
+
+// encode returns the representation of the world, but for its corpi.
+func (w *World) encode() (worldJSON, error) {
+    w.sync()
+    v := worldJSON{
+        IDs:            w.ids,
+        LastID:         w.lastID,
+        G:              w.G,
+        K:              w.K,
+        Softening:      w.Softening,
+        Restitution:    w.Restitution,
+        Width:          w.Width,
+        Height:         w.Height,
+        QueryMode:      w.QueryMode,
+        MergeOnContact: w.MergeOnContact,
+        SleepSpeed:     w.SleepSpeed,
+        SleepAcc:       w.SleepAcc,
+        SleepSteps:     w.SleepSteps,
+        Workers:        w.Workers,
+        Accum:          w.accum,
+        Iterations:     w.iterations,
+        FrameDelay:     w.FrameDelay,
+    }
+    switch bp := w.Broadphase.(type) {
+    case nil:
+    case *SpatialHash:
+        v.Broadphase = &broadphaseJSON{Type: "spatialHash", CellSize: bp.CellSize}
+    case *Quadtree:
+        v.Broadphase = &broadphaseJSON{Type: "quadtree", Capacity: bp.Capacity, MaxDepth: bp.MaxDepth}
+    default:
+        return v, fmt.Errorf("corpus: cannot encode broadphase %T", bp)
+    }
+    return v, nil
+}
+
+// decode sets the world to the given representation and corpi.
+func (w *World) decode(v worldJSON, corpi []Corpus) error {
+    if len(v.IDs) != len(corpi) {
+        return fmt.Errorf("corpus: World has %d corpi but %d IDs", len(corpi), len(v.IDs))
+    }
+    var bp Broadphase
+    if v.Broadphase != nil {
+        switch v.Broadphase.Type {
+        case "spatialHash":
+            bp = &SpatialHash{CellSize: v.Broadphase.CellSize}
+        case "quadtree":
+            bp = &Quadtree{Capacity: v.Broadphase.Capacity, MaxDepth: v.Broadphase.MaxDepth}
+        default:
+            return fmt.Errorf("corpus: unknown broadphase %q", v.Broadphase.Type)
+        }
+    }
+    w.Corpi, w.ids, w.lastID = corpi, v.IDs, v.LastID
+    w.G, w.K, w.Softening, w.Restitution = v.G, v.K, v.Softening, v.Restitution
+    w.Width, w.Height = v.Width, v.Height
+    w.Broadphase, w.QueryMode, w.MergeOnContact = bp, v.QueryMode, v.MergeOnContact
+    w.SleepSpeed, w.SleepAcc, w.SleepSteps = v.SleepSpeed, v.SleepAcc, v.SleepSteps
+    w.Workers, w.accum, w.iterations = v.Workers, v.Accum, v.Iterations
+    w.FrameDelay = v.FrameDelay
+    w.Invalidate()
+    return nil
+}
+
+// corpusGob is the gob representation of a Corpus, flat for compactness.
+type corpusGob struct {
+    Pos, Vel, Acc, Force, PrevAcc              Vector
+    Mass, Charge, Radius, Life, MaxSpeed       float64
+    Immaterial, Static, Mortal, Verlet, Asleep bool
+    Tag                                        string
+    Color                                      color.RGBA
+    Layer, Mask                                uint32
+    Idle                                       int
+}
+
+// worldGob is the gob representation of a World.
+type worldGob struct {
+    World worldJSON
+    Corpi []corpusGob
+}
+
+// gob returns the gob representation of the corpus.
+func (c Corpus) gob() corpusGob {
+    return corpusGob{
+        Pos: c.Pos, Vel: c.Vel, Acc: c.Acc, Force: c.Force, PrevAcc: c.prevAcc,
+        Mass: c.Mass, Charge: c.Charge, Radius: c.Radius, Life: c.Life, MaxSpeed: c.maxSpeed,
+        Immaterial: c.Immaterial, Static: c.Static, Mortal: c.mortal, Verlet: c.verlet,
+        Asleep: c.asleep, Tag: c.Tag, Layer: c.Layer, Mask: c.Mask, Idle: c.idle,
+        Color: c.Color,
+    }
+}
+
+// corpus returns the corpus of the gob representation.
+func (v corpusGob) corpus() Corpus {
+    return Corpus{
+        Pos: v.Pos, Vel: v.Vel, Acc: v.Acc, Force: v.Force, prevAcc: v.PrevAcc,
+        Mass: v.Mass, Charge: v.Charge, Radius: v.Radius, Life: v.Life, maxSpeed: v.MaxSpeed,
+        Immaterial: v.Immaterial, Static: v.Static, mortal: v.Mortal, verlet: v.Verlet,
+        asleep: v.Asleep, Tag: v.Tag, Layer: v.Layer, Mask: v.Mask, idle: v.Idle,
+        Color: v.Color,
+    }
+}
+
+// MarshalBinary encodes the corpus with gob, keeping everything
+// MarshalJSON does.
+func (c Corpus) MarshalBinary() ([]byte, error) {
+    var buf bytes.Buffer
+    err := gob.NewEncoder(&buf).Encode(c.gob())
+    return buf.Bytes(), err
+}
+
+// UnmarshalBinary decodes a corpus encoded by MarshalBinary.
+func (c *Corpus) UnmarshalBinary(data []byte) error {
+    var v corpusGob
+    if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+        return err
+    }
+    *c = v.corpus()
+    return nil
+}
+
+// MarshalBinary encodes the world with gob, keeping everything MarshalJSON
+// does in a faster and more compact form, for checkpoints of large worlds.
+func (w *World) MarshalBinary() ([]byte, error) {
+    v, err := w.encode()
+    if err != nil {
+        return nil, err
+    }
+    corpi := make([]corpusGob, len(w.Corpi))
+    for idx := range w.Corpi {
+        corpi[idx] = w.Corpi[idx].gob()
+    }
+    var buf bytes.Buffer
+    err = gob.NewEncoder(&buf).Encode(worldGob{World: v, Corpi: corpi})
+    return buf.Bytes(), err
+}
+
+// UnmarshalBinary decodes a world encoded by MarshalBinary, leaving what
+// it cannot encode as it was, like UnmarshalJSON.
+func (w *World) UnmarshalBinary(data []byte) error {
+    var v worldGob
+    if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+        return err
+    }
+    corpi := make([]Corpus, len(v.Corpi))
+    for idx := range v.Corpi {
+        corpi[idx] = v.Corpi[idx].corpus()
+    }
+    return w.decode(v.World, corpi)
+}
@@ -0,0 +1,202 @@
+package corpus
+
+import (
+	"encoding/json"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func TestCorpus_MarshalJSON(t *testing.T) {
+	c := MakeCorpus(1, 2, 3, 4, 5, 0, 6)
+	data, err := json.Marshal(c)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"pos", "vel", "mass", "radius"} {
+		if _, ok := m[key]; !ok {
+			t.Error("WRONG !! missing", key)
+		}
+	}
+
+	for _, key := range []string{"acc", "charge", "immaterial", "color"} {
+		if _, ok := m[key]; ok {
+			t.Error("WRONG !! unexpected", key)
+		}
+	}
+}
+
+func TestCorpus_UnmarshalJSON(t *testing.T) {
+	cs := []Corpus{
+		MakeCorpus(1, 2, 3, 4, 5, 0, 6),
+		{Pos: Vector{-1, 0.5}, Vel: Vector{2, 2}, Acc: Vector{0, -9.8}, Force: Vector{1, 1}, Mass: 3, Charge: -1, Radius: 2, Immaterial: true, Static: true, Tag: "ghost", Layer: 2, Mask: 5, maxSpeed: 10, Life: 2, mortal: true, idle: 3, asleep: true, prevAcc: Vector{1, 2}, verlet: true},
+	}
+
+	for _, c := range cs {
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res Corpus
+		if err := json.Unmarshal(data, &res); err != nil {
+			t.Fatal(err)
+		}
+
+		if res != c {
+			t.Error("WRONG !!")
+		}
+	}
+}
+
+func TestVector_MarshalJSON(t *testing.T) {
+	a := Vector{1, -2.5}
+	data, err := json.Marshal(a)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != `{"x":1,"y":-2.5}` {
+		t.Error("WRONG !!")
+	}
+
+	var res Vector
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if res != a {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_MarshalJSON(t *testing.T) {
+	w := chargedWorld(rand.New(rand.NewSource(1)), 40, 100)
+	w.Width, w.Height, w.Restitution = 100, 100, 0.9
+	w.Broadphase = &Quadtree{Capacity: 4}
+	w.SleepSpeed, w.SleepAcc, w.SleepSteps = 0.01, 0.01, 3
+	w.FrameDelay = 10
+	w.Corpi[0].SetLife(0.5)
+	w.Corpi[1].Static = true
+	w.Remove(w.IDs()[2])
+	for i := 0; i < 10; i++ {
+		w.Step(0.1)
+	}
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewWorld(0, 0)
+	if err := json.Unmarshal(data, r); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both carry on identically.
+	for i := 0; i < 10; i++ {
+		w.Step(0.1)
+		r.Step(0.1)
+	}
+
+	if len(r.Corpi) != len(w.Corpi) || !sameIDs(r.IDs(), w.IDs()) || r.Add(Corpus{}) != w.Add(Corpus{}) || r.FrameDelay != 10 {
+		t.Fatal("WRONG !!")
+	}
+
+	for idx := range w.Corpi {
+		if r.Corpi[idx] != w.Corpi[idx] {
+			t.Error("WRONG !!")
+			break
+		}
+	}
+
+	w.Broadphase = struct{ Broadphase }{}
+
+	if _, err := json.Marshal(w); err == nil {
+		t.Error("WRONG !!")
+	}
+
+	if err := json.Unmarshal([]byte(`{"corpi": [{}], "ids": []}`), r); err == nil {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestCorpus_MarshalBinary(t *testing.T) {
+	c := Corpus{Pos: Vector{-1, 0.5}, Vel: Vector{2, 2}, Acc: Vector{0, -9.8}, Force: Vector{1, 1}, Mass: 3, Charge: -1, Radius: 2, Immaterial: true, Static: true, Tag: "ghost", Layer: 2, Mask: 5, maxSpeed: 10, Life: 2, mortal: true, idle: 3, asleep: true, prevAcc: Vector{1, 2}, verlet: true, Color: color.RGBA{1, 2, 3, 4}}
+	data, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d Corpus
+	if err := d.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if d != c {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_MarshalBinary(t *testing.T) {
+	w := chargedWorld(rand.New(rand.NewSource(1)), 40, 100)
+	w.Width, w.Height = 100, 100
+	w.SetBroadphase(4)
+	w.FrameDelay = 10
+	w.Corpi[0].SetLife(0.5)
+	w.Remove(w.IDs()[2])
+	for i := 0; i < 10; i++ {
+		w.Step(0.1)
+	}
+
+	data, err := w.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewWorld(0, 0)
+	if err := r.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if !sameIDs(r.IDs(), w.IDs()) || r.Broadphase.(*SpatialHash).CellSize != 4 || r.K != w.K || r.FrameDelay != 10 {
+		t.Fatal("WRONG !!")
+	}
+
+	for i := 0; i < 10; i++ {
+		w.Step(0.1)
+		r.Step(0.1)
+	}
+	for idx := range w.Corpi {
+		if r.Corpi[idx] != w.Corpi[idx] {
+			t.Error("WRONG !!")
+			break
+		}
+	}
+}
+
+func benchmarkWorldEncode(b *testing.B, marshal func(w *World) ([]byte, error), unmarshal func(w *World, data []byte) error) {
+	w := chargedWorld(rand.New(rand.NewSource(1)), 10000, 1000)
+	var size int
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		data, _ := marshal(w)
+		unmarshal(NewWorld(0, 0), data)
+		size = len(data)
+	}
+	b.ReportMetric(float64(size), "bytes")
+}
+
+func BenchmarkWorld_MarshalBinary(b *testing.B) {
+	benchmarkWorldEncode(b, (*World).MarshalBinary, (*World).UnmarshalBinary)
+}
+
+func BenchmarkWorld_MarshalJSON(b *testing.B) {
+	benchmarkWorldEncode(b, (*World).MarshalJSON, (*World).UnmarshalJSON)
+}
@@ -0,0 +1,79 @@
+package corpus
+
+import "math"
+
+// ForceGenerator applies a force to a corpus, such as a field it is in.
+// The generators of a World are applied to each of its material corpi
+// every Step, before they are integrated.
+type ForceGenerator interface {
+    Apply(c *Corpus)
+}
+
+// UniformGravity is a ForceGenerator of a uniform gravitational field,
+// accelerating every corpus by G whatever its mass.
+type UniformGravity struct {
+    G Vector
+}
+
+// Apply subjects the corpus to the field.
+func (g UniformGravity) Apply(c *Corpus) {
+    c.ApplyGravity(g.G)
+}
+
+// DragField is a ForceGenerator of a fluid slowing every corpus down
+// with a linear drag of coefficient K.
+type DragField struct {
+    K float64
+}
+
+// Apply subjects the corpus to the drag.
+func (d DragField) Apply(c *Corpus) {
+    c.ApplyDrag(d.K)
+}
+
+// Vortex is a ForceGenerator of a whirlpool around Center, swirling corpi
+// counter-clockwise for a positive Strength while pulling them inwards by
+// Inward times as much, both fading with the distance r from the center.
+// F = Strength/r * (t - Inward*n), t and n the unit tangent and normal.
+// The distance is never taken below the corpus radius, so the force stays
+// finite at the center.
+type Vortex struct {
+    Center           Vector
+    Strength, Inward float64
+}
+
+// Apply subjects the corpus to the vortex.
+func (v Vortex) Apply(c *Corpus) {
+    r := c.Pos.Sub(v.Center)
+    dist := r.Mag()
+    if dist == 0 {
+        return
+    }
+    n := r.Div(dist)
+    c.ApplyForce(n.Perp().Sub(n.Mult(v.Inward)).Mult(v.Strength / math.Max(dist, c.Radius)))
+}
+
+// Wind is a ForceGenerator of a wind whose force on a corpus depends on
+// its position, so it can vary across space like gusts do.
+type Wind func(pos Vector) Vector
+
+// Apply subjects the corpus to the wind at its position.
+func (wind Wind) Apply(c *Corpus) {
+    c.ApplyForce(wind(c.Pos))
+}
+
+// AddGenerator registers the force generator with the world.
+func (w *World) AddGenerator(g ForceGenerator) {
+    w.Generators = append(w.Generators, g)
+}
+
+// generate applies the force generators to every material corpus.
+func (w *World) generate() {
+    for _, g := range w.Generators {
+        for idx := range w.Corpi {
+            if !w.Corpi[idx].Immaterial {
+                g.Apply(&w.Corpi[idx])
+            }
+        }
+    }
+}
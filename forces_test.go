@@ -0,0 +1,96 @@
+package corpus
+
+import (
+	"testing"
+)
+
+// push is a ForceGenerator pushing every corpus along +x.
+type push float64
+
+func (p push) Apply(c *Corpus) {
+	c.ApplyForce(Vector{float64(p), 0})
+}
+
+func TestWorld_AddGenerator(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(Corpus{Pos: Vector{0, 0}, Mass: 2, Radius: 1})
+	w.Add(Corpus{Pos: Vector{10, 0}, Mass: 4, Radius: 1})
+	w.Add(Corpus{Pos: Vector{20, 0}, Mass: 1, Radius: 1, Immaterial: true})
+	w.AddGenerator(push(4))
+	w.Step(1)
+
+	if w.Corpi[0].Vel != (Vector{2, 0}) || w.Corpi[1].Vel != (Vector{1, 0}) || !w.Corpi[2].Vel.IsZero() {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestUniformGravity(t *testing.T) {
+	c := Corpus{Mass: 3}
+	UniformGravity{G: Vector{0, 9.8}}.Apply(&c)
+
+	if !c.Acc.Equals(Vector{0, 9.8}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestDragField(t *testing.T) {
+	c := Corpus{Vel: Vector{2, -4}, Mass: 2}
+	DragField{K: 0.5}.Apply(&c)
+
+	if !c.Acc.Equals(Vector{-0.5, 1}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVortex(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(Corpus{Pos: Vector{5, 0}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{50, 0}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{-10, -10}, Mass: 1, Radius: 1})
+	w.AddGenerator(Vortex{Center: Vector{0, 0}, Strength: 10, Inward: 0.5})
+	w.Step(0.1)
+
+	// Counter-clockwise and inwards.
+	if !w.Corpi[0].Vel.Equals(Vector{-0.1, 0.2}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	// Weaker further out.
+	if w.Corpi[1].Vel.Mag() >= w.Corpi[0].Vel.Mag()/5 {
+		t.Error("WRONG !!")
+	}
+
+	if w.Corpi[2].Vel.Dot(Vector{1, -1}) <= 0 {
+		t.Error("WRONG !!")
+	}
+
+	// Nothing at the center itself.
+	c := Corpus{Mass: 1, Radius: 1}
+	Vortex{Strength: 10, Inward: 1}.Apply(&c)
+
+	if !c.Acc.IsZero() {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWind(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(Corpus{Pos: Vector{0, 0}, Mass: 2, Radius: 1})
+	w.Add(Corpus{Pos: Vector{10, 0}, Radius: 1, Immaterial: true})
+	w.AddGenerator(Wind(func(pos Vector) Vector { return Vector{4, 0} }))
+	w.Step(1)
+
+	if w.Corpi[0].Pos.X <= 0 || w.Corpi[0].Vel != (Vector{2, 0}) || !w.Corpi[1].Vel.IsZero() {
+		t.Error("WRONG !!")
+	}
+
+	// Gusting harder with height.
+	gust := Wind(func(pos Vector) Vector { return Vector{pos.Y, 0} })
+	low, high := Corpus{Pos: Vector{0, 1}, Mass: 1}, Corpus{Pos: Vector{0, 3}, Mass: 1}
+	gust.Apply(&low)
+	gust.Apply(&high)
+
+	if low.Acc != (Vector{1, 0}) || high.Acc != (Vector{3, 0}) {
+		t.Error("WRONG !!")
+	}
+}
@@ -0,0 +1,60 @@
+package corpus
+
+import (
+    "fmt"
+    "image"
+    "image/color/palette"
+    "image/gif"
+    "io"
+    "math"
+)
+
+// RecordGIF steps the world the given number of times by dt and encodes
+// the frame drawn after each step as an animated GIF of the given size,
+// with w.FrameDelay between frames. The frames show the same area as
+// WriteSVG, each corpus a disk of its color, matched in the Plan 9
+// palette. Returns an error without stepping if steps, width or height
+// is not positive.
+func (w *World) RecordGIF(out io.Writer, steps int, dt float64, width, height int) error {
+    if steps <= 0 {
+        return fmt.Errorf("corpus: RecordGIF needs at least 1 step, got %d", steps)
+    }
+    if width <= 0 || height <= 0 {
+        return fmt.Errorf("corpus: RecordGIF needs a positive size, got %dx%d", width, height)
+    }
+    delay := w.FrameDelay
+    if delay <= 0 {
+        delay = 4
+    }
+    anim := &gif.GIF{}
+    for step := 0; step < steps; step++ {
+        w.Step(dt)
+        anim.Image = append(anim.Image, w.frame(width, height))
+        anim.Delay = append(anim.Delay, delay)
+    }
+    return gif.EncodeAll(out, anim)
+}
+
+// frame draws the world on a black image of the given size with the Plan 9
+// palette.
+func (w *World) frame(width, height int) *image.Paletted {
+    img := image.NewPaletted(image.Rect(0, 0, width, height), palette.Plan9)
+    min, max := w.view()
+    scale := math.Min(float64(width)/(max.X-min.X), float64(height)/(max.Y-min.Y))
+    for _, c := range w.Corpi {
+        center := c.Pos.Sub(min).Mult(scale)
+        rad := c.Radius * scale
+        col := c.drawColor()
+        x0, y0 := int(math.Floor(center.X-rad)), int(math.Floor(center.Y-rad))
+        x1, y1 := int(math.Ceil(center.X+rad)), int(math.Ceil(center.Y+rad))
+        for y := y0; y <= y1; y++ {
+            for x := x0; x <= x1; x++ {
+                // Pixels whose centers are inside the disk.
+                if (Vector{float64(x) + 0.5, float64(y) + 0.5}).DistSq(center) <= rad*rad {
+                    img.Set(x, y, col)
+                }
+            }
+        }
+    }
+    return img
+}
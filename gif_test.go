@@ -0,0 +1,54 @@
+package corpus
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+func TestWorld_RecordGIF(t *testing.T) {
+	w := NewWorld(100, 100)
+	w.Add(Corpus{Pos: Vector{20, 50}, Vel: Vector{10, 0}, Mass: 1, Radius: 10})
+	w.FrameDelay = 10
+	var buf bytes.Buffer
+
+	if err := w.RecordGIF(&buf, 3, 1, 50, 50); err != nil {
+		t.Fatal(err)
+	}
+
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(anim.Image) != 3 || anim.Delay[0] != 10 || anim.Image[0].Bounds().Dx() != 50 {
+		t.Fatal("WRONG !!")
+	}
+
+	// The corpus moves right, at half scale.
+	white := color.RGBAModel.Convert(color.White)
+	for i, img := range anim.Image {
+		x := 15 + 5*i
+
+		if color.RGBAModel.Convert(img.At(x, 25)) != white || color.RGBAModel.Convert(img.At(x+8, 25)) == white {
+			t.Error("WRONG !!")
+		}
+	}
+
+	// Without steps there is nothing to record.
+	pos := w.Corpi[0].Pos
+
+	if err := w.RecordGIF(&buf, 0, 1, 50, 50); err == nil || w.Corpi[0].Pos != pos {
+		t.Error("WRONG !!")
+	}
+
+	// Nor without pixels.
+	if err := w.RecordGIF(&buf, 3, 1, 0, 50); err == nil || w.Corpi[0].Pos != pos {
+		t.Error("WRONG !!")
+	}
+
+	if err := w.RecordGIF(&buf, 3, 1, 50, -1); err == nil || w.Corpi[0].Pos != pos {
+		t.Error("WRONG !!")
+	}
+}
@@ -0,0 +1,52 @@
+package corpus
+
+import (
+    "encoding/csv"
+    "io"
+    "strconv"
+)
+
+// TrajectoryLogger writes the positions and velocities of the corpi of a
+// World as CSV, one row per corpus per call to Log, under the header
+// "step,id,posX,posY,velX,velY".
+type TrajectoryLogger struct {
+    w      *csv.Writer
+    step   int
+    header bool // whether the header was written
+}
+
+// NewTrajectoryLogger returns a TrajectoryLogger writing to w.
+func NewTrajectoryLogger(w io.Writer) *TrajectoryLogger {
+    return &TrajectoryLogger{w: csv.NewWriter(w)}
+}
+
+// Log writes a row for every corpus of the world as it is now, numbering
+// the calls from 0 as the steps, so calling it after every Step logs the
+// whole run. The rows are flushed to the writer before returning, so
+// nothing builds up in memory however long the run.
+func (l *TrajectoryLogger) Log(w *World) error {
+    if !l.header {
+        l.w.Write([]string{"step", "id", "posX", "posY", "velX", "velY"})
+        l.header = true
+    }
+    step := strconv.Itoa(l.step)
+    for idx, id := range w.IDs() {
+        c := &w.Corpi[idx]
+        l.w.Write([]string{
+            step,
+            strconv.FormatUint(uint64(id), 10),
+            formatFloat(c.Pos.X),
+            formatFloat(c.Pos.Y),
+            formatFloat(c.Vel.X),
+            formatFloat(c.Vel.Y),
+        })
+    }
+    l.step++
+    l.w.Flush()
+    return l.w.Error()
+}
+
+// formatFloat formats x in the shortest form that parses back exactly.
+func formatFloat(x float64) string {
+    return strconv.FormatFloat(x, 'g', -1, 64)
+}
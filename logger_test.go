@@ -0,0 +1,33 @@
+package corpus
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTrajectoryLogger_Log(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(Corpus{Pos: Vector{0, 1}, Vel: Vector{0.5, -2}, Mass: 1, Radius: 1})
+	var buf bytes.Buffer
+	l := NewTrajectoryLogger(&buf)
+
+	for i := 0; i < 2; i++ {
+		w.Step(1)
+		if err := l.Log(w); err != nil {
+			t.Fatal(err)
+		}
+
+		// Flushed right away.
+		if bytes.Count(buf.Bytes(), []byte("\n")) != 2+i {
+			t.Error("WRONG !!")
+		}
+	}
+
+	want := "step,id,posX,posY,velX,velY\n" +
+		"0,1,0.5,-1,0.5,-2\n" +
+		"1,1,1,-3,0.5,-2\n"
+
+	if buf.String() != want {
+		t.Error("WRONG !!", buf.String())
+	}
+}
@@ -0,0 +1,64 @@
+package corpus
+
+import (
+    "sync"
+)
+
+// parallelChunk is the number of corpi a worker takes at a time.
+const parallelChunk = 64
+
+// interactParallel accumulates gravity and the electrostatic force between
+// every pair of corpi like GravitateAll and CoulombAll, spread over
+// w.Workers goroutines. Each corpus only has its own net force written,
+// by a single worker, summing the forces on it in the same order and with
+// the same arithmetic as the serial functions, so the results are
+// bit-identical to theirs.
+func (w *World) interactParallel() {
+    next := make(chan int)
+    var wg sync.WaitGroup
+    for worker := 0; worker < w.Workers; worker++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for start := range next {
+                for idx := start; idx < start+parallelChunk && idx < len(w.Corpi); idx++ {
+                    w.interactOn(idx)
+                }
+            }
+        }()
+    }
+    for start := 0; start < len(w.Corpi); start += parallelChunk {
+        next <- start
+    }
+    close(next)
+    wg.Wait()
+}
+
+// interactOn accumulates on the corpus at idx the forces from all the others.
+// Forces within a pair are computed from the lower index, as the serial
+// functions do, and negated for the other corpus.
+func (w *World) interactOn(idx int) {
+    c := &w.Corpi[idx]
+    if w.G != 0 {
+        for other := range w.Corpi {
+            if other < idx {
+                if force, ok := w.Corpi[other].gravity(c, w.G, w.Softening); ok {
+                    c.AddForce(force.Neg())
+                }
+            } else if force, ok := c.gravity(&w.Corpi[other], w.G, w.Softening); ok {
+                c.AddForce(force)
+            }
+        }
+    }
+    if w.K != 0 {
+        for other := range w.Corpi {
+            if other < idx {
+                if force, ok := w.Corpi[other].coulomb(c, w.K); ok {
+                    c.AddForce(force.Neg())
+                }
+            } else if force, ok := c.coulomb(&w.Corpi[other], w.K); ok {
+                c.AddForce(force)
+            }
+        }
+    }
+}
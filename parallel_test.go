@@ -0,0 +1,52 @@
+package corpus
+
+import (
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+// chargedWorld returns a world of n charged corpi of random mass scattered
+// over a size by size square, with gravity, electrostatics and softening.
+func chargedWorld(r *rand.Rand, n int, size float64) *World {
+	w := gravityWorld(r, n, size)
+	w.K = 2
+	for idx := range w.Corpi {
+		w.Corpi[idx].Charge = r.Float64()*2 - 1
+	}
+	return w
+}
+
+// Run with -race to check the workers only touch their own corpi.
+func TestWorld_Workers(t *testing.T) {
+	serial := chargedWorld(rand.New(rand.NewSource(1)), 300, 100)
+	serial.Corpi[5].Immaterial = true
+	serial.Corpi[6].Static = true
+	parallel := NewWorld(0, 0)
+	*parallel = *serial
+	parallel.Corpi = append([]Corpus(nil), serial.Corpi...)
+	parallel.Workers = 4
+
+	for step := 0; step < 20; step++ {
+		serial.Step(0.01)
+		parallel.Step(0.01)
+	}
+
+	// Bit-identical.
+	for idx := range serial.Corpi {
+		if serial.Corpi[idx] != parallel.Corpi[idx] {
+			t.Error("WRONG !!")
+			break
+		}
+	}
+}
+
+func BenchmarkWorld_Step_Workers(b *testing.B) {
+	w := chargedWorld(rand.New(rand.NewSource(1)), 2000, 1000)
+	w.Workers = runtime.GOMAXPROCS(0) // scale with -cpu 1,2,4,8
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		w.Step(0.01)
+	}
+}
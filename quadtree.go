@@ -0,0 +1,194 @@
+package corpus
+
+import "sort"
+
+// Quadtree is a Broadphase that recursively splits space into quadrants
+// where corpi crowd together, so it adapts to clustered distributions on
+// which a uniform SpatialHash degenerates. The tree is rebuilt from the
+// current positions on every call to Pairs.
+type Quadtree struct {
+    // Capacity is the number of corpi a node holds before it splits,
+    // 8 if not positive.
+    Capacity int
+    // MaxDepth bounds the depth of the tree, 16 if not positive.
+    MaxDepth int
+}
+
+// quadNode is a node of a Quadtree covering the box from min to max.
+// Each corpus is held by the deepest node whose box contains its bounds.
+type quadNode struct {
+    min, max Vector
+    items    []int
+    children []quadNode
+}
+
+// Pairs returns the pairs of material corpi whose bounding boxes overlap.
+func (q *Quadtree) Pairs(corpi []Corpus) []Pair {
+    root, ok := q.build(corpi, false)
+    if !ok {
+        return nil
+    }
+    var pairs []Pair
+    root.pairs(corpi, nil, &pairs)
+    sortPairs(pairs)
+    return pairs
+}
+
+// Index returns an index of all corpi, immaterial included.
+func (q *Quadtree) Index(corpi []Corpus) Index {
+    root, _ := q.build(corpi, true)
+    min, max := bounds(corpi)
+    return &quadIndex{root: root, min: min, max: max}
+}
+
+// quadIndex is the Index of a Quadtree.
+type quadIndex struct {
+    root     *quadNode // nil if there are no corpi
+    min, max []Vector  // bounding boxes of the corpi
+}
+
+// Query returns the indices of the corpi whose bounding boxes overlap
+// the box from min to max, in increasing order.
+func (x *quadIndex) Query(min, max Vector) []int {
+    var found []int
+    if x.root != nil {
+        x.root.query(x, min, max, &found)
+    }
+    sort.Ints(found)
+    return found
+}
+
+// query appends the corpi of the node and its descendants whose bounding
+// boxes overlap the box from min to max.
+func (n *quadNode) query(x *quadIndex, min, max Vector, found *[]int) {
+    for _, idx := range n.items {
+        if boxesOverlap(min, max, x.min[idx], x.max[idx]) {
+            *found = append(*found, idx)
+        }
+    }
+    for idx := range n.children {
+        child := &n.children[idx]
+        if boxesOverlap(min, max, child.min, child.max) {
+            child.query(x, min, max, found)
+        }
+    }
+}
+
+// build returns the root of a tree holding the material corpi, or all
+// corpi if all is true, and false if there are none.
+func (q *Quadtree) build(corpi []Corpus, all bool) (*quadNode, bool) {
+    capacity, maxDepth := q.Capacity, q.MaxDepth
+    if capacity <= 0 {
+        capacity = 8
+    }
+    if maxDepth <= 0 {
+        maxDepth = 16
+    }
+
+    root := &quadNode{}
+    first := true
+    for idx := range corpi {
+        if corpi[idx].Immaterial && !all {
+            continue
+        }
+        min, max := corpi[idx].Bounds()
+        if first {
+            root.min, root.max = min, max
+            first = false
+        }
+        root.min, root.max = root.min.Min(min), root.max.Max(max)
+    }
+    if first {
+        return nil, false
+    }
+
+    for idx := range corpi {
+        if !corpi[idx].Immaterial || all {
+            root.insert(corpi, idx, 0, capacity, maxDepth)
+        }
+    }
+    return root, true
+}
+
+// insert adds the corpus at idx to the deepest node containing it,
+// splitting nodes that grow beyond capacity.
+func (n *quadNode) insert(corpi []Corpus, idx, depth, capacity, maxDepth int) {
+    if n.children != nil {
+        if child := n.childFor(corpi[idx]); child != nil {
+            child.insert(corpi, idx, depth+1, capacity, maxDepth)
+            return
+        }
+    }
+    n.items = append(n.items, idx)
+    if n.children == nil && len(n.items) > capacity && depth < maxDepth {
+        n.split(corpi, depth, capacity, maxDepth)
+    }
+}
+
+// split divides the node into four quadrants and moves down the corpi
+// that fit entirely inside one of them.
+func (n *quadNode) split(corpi []Corpus, depth, capacity, maxDepth int) {
+    mid := n.min.Midpoint(n.max)
+    n.children = []quadNode{
+        {min: n.min, max: mid},
+        {min: Vector{mid.X, n.min.Y}, max: Vector{n.max.X, mid.Y}},
+        {min: Vector{n.min.X, mid.Y}, max: Vector{mid.X, n.max.Y}},
+        {min: mid, max: n.max},
+    }
+    items := n.items
+    n.items = nil
+    for _, idx := range items {
+        if child := n.childFor(corpi[idx]); child != nil {
+            child.insert(corpi, idx, depth+1, capacity, maxDepth)
+        } else {
+            n.items = append(n.items, idx)
+        }
+    }
+}
+
+// childFor returns the child whose box contains the bounds of c, or nil
+// if c straddles several children.
+func (n *quadNode) childFor(c Corpus) *quadNode {
+    min, max := c.Bounds()
+    for idx := range n.children {
+        child := &n.children[idx]
+        if min.X >= child.min.X && min.Y >= child.min.Y && max.X <= child.max.X && max.Y <= child.max.Y {
+            return child
+        }
+    }
+    return nil
+}
+
+// pairs appends the overlapping pairs of the corpi held by the node and its
+// descendants, with each other and with the corpi held by its ancestors.
+func (n *quadNode) pairs(corpi []Corpus, ancestors []int, pairs *[]Pair) {
+    for i, a := range n.items {
+        minA, maxA := corpi[a].Bounds()
+        for _, b := range ancestors {
+            minB, maxB := corpi[b].Bounds()
+            if boxesOverlap(minA, maxA, minB, maxB) {
+                *pairs = append(*pairs, makePair(a, b))
+            }
+        }
+        for _, b := range n.items[i+1:] {
+            minB, maxB := corpi[b].Bounds()
+            if boxesOverlap(minA, maxA, minB, maxB) {
+                *pairs = append(*pairs, makePair(a, b))
+            }
+        }
+    }
+    if n.children != nil {
+        ancestors = append(ancestors[:len(ancestors):len(ancestors)], n.items...)
+        for idx := range n.children {
+            n.children[idx].pairs(corpi, ancestors, pairs)
+        }
+    }
+}
+
+// makePair returns the pair of the two indices in increasing order.
+func makePair(a, b int) Pair {
+    if a > b {
+        a, b = b, a
+    }
+    return Pair{a, b}
+}
@@ -0,0 +1,80 @@
+package corpus
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// clusteredCorpi returns n corpi of random radius up to maxRad, gathered
+// in a few tight clusters spread over a size by size square.
+func clusteredCorpi(r *rand.Rand, n int, size, maxRad float64) []Corpus {
+	centers := make([]Vector, 5)
+	for idx := range centers {
+		centers[idx] = Vector{r.Float64() * size, r.Float64() * size}
+	}
+	corpi := make([]Corpus, n)
+	for idx := range corpi {
+		center := centers[r.Intn(len(centers))]
+		corpi[idx] = Corpus{
+			Pos:    center.Add(Vector{r.NormFloat64(), r.NormFloat64()}.Mult(size / 50)),
+			Mass:   1,
+			Radius: 0.1 + r.Float64()*maxRad,
+		}
+	}
+	return corpi
+}
+
+func TestQuadtree_Pairs(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	corpi := clusteredCorpi(r, 1000, 1000, 2)
+	corpi[7].Immaterial = true
+	pairs := (&Quadtree{}).Pairs(corpi)
+
+	checkPairs(t, corpi, pairs)
+
+	for _, p := range pairs {
+		if p.A == 7 || p.B == 7 {
+			t.Error("WRONG !!")
+		}
+	}
+
+	// The same pairs as the spatial hash.
+	grid := (&SpatialHash{CellSize: 4}).Pairs(corpi)
+
+	if len(grid) != len(pairs) {
+		t.Error("WRONG !!")
+	}
+	for idx := range grid {
+		if idx < len(pairs) && grid[idx] != pairs[idx] {
+			t.Error("WRONG !!")
+			break
+		}
+	}
+
+	// A tiny capacity and depth still find every pair.
+	checkPairs(t, corpi, (&Quadtree{Capacity: 1, MaxDepth: 3}).Pairs(corpi))
+
+	if (&Quadtree{}).Pairs(nil) != nil {
+		t.Error("WRONG !!")
+	}
+}
+
+func BenchmarkQuadtree_Pairs_Clustered(b *testing.B) {
+	corpi := clusteredCorpi(rand.New(rand.NewSource(1)), 5000, 1000, 2)
+	q := &Quadtree{}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		q.Pairs(corpi)
+	}
+}
+
+func BenchmarkSpatialHash_Pairs_Clustered(b *testing.B) {
+	corpi := clusteredCorpi(rand.New(rand.NewSource(1)), 5000, 1000, 2)
+	h := &SpatialHash{CellSize: 40}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.Pairs(corpi)
+	}
+}
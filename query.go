@@ -0,0 +1,169 @@
+package corpus
+
+import (
+    "math"
+    "sort"
+)
+
+// QueryMode selects which corpi the spatial queries of a World return.
+type QueryMode int
+
+const (
+    // QueryCenter returns the corpi whose centers lie inside the area.
+    QueryCenter QueryMode = iota
+    // QueryOverlap returns the corpi whose bodies overlap the area,
+    // touching included.
+    QueryOverlap
+)
+
+// QueryRadius returns the IDs of the corpi within radius of the center,
+// immaterial ones included, in the order of Corpi. Depending on
+// w.QueryMode, a corpus is within radius if its center is or if any of
+// its body is, "||x-center|| <= radius (+ r)".
+func (w *World) QueryRadius(center Vector, radius float64) []ID {
+    var found []ID
+    for _, idx := range w.candidates(center.Sub(Vector{radius, radius}), center.Add(Vector{radius, radius})) {
+        reach := radius
+        if w.QueryMode == QueryOverlap {
+            reach += w.Corpi[idx].Radius
+        }
+        if w.Corpi[idx].Pos.DistSq(center) <= reach*reach {
+            found = append(found, w.ids[idx])
+        }
+    }
+    return found
+}
+
+// QueryRect returns the IDs of the corpi inside the rectangle with the
+// given opposite corners, immaterial ones included, in the order of Corpi.
+// Depending on w.QueryMode, a corpus is inside if its center is, edges
+// included, or if its body overlaps the rectangle at all.
+func (w *World) QueryRect(min, max Vector) []ID {
+    min, max = min.Min(max), min.Max(max)
+    var found []ID
+    for _, idx := range w.candidates(min, max) {
+        c := &w.Corpi[idx]
+        reach := 0.0
+        if w.QueryMode == QueryOverlap {
+            reach = c.Radius
+        }
+        if c.Pos.ClampRect(min, max).DistSq(c.Pos) <= reach*reach {
+            found = append(found, w.ids[idx])
+        }
+    }
+    return found
+}
+
+// Raycast returns the ID of the first material corpus hit by the ray from
+// origin in the direction dir within maxDist, the point on its surface
+// where it is hit, and whether any corpus is hit. Corpi containing the
+// origin are ignored, so a corpus can see past itself.
+// ||origin + t*dir - x|| = r, solved for the smallest t >= 0
+func (w *World) Raycast(origin, dir Vector, maxDist float64) (ID, Vector, bool) {
+    w.sync()
+    u := dir.Norm()
+    if u.IsZero() {
+        return 0, Vector{}, false
+    }
+    hit, nearest := -1, maxDist
+    for idx := range w.Corpi {
+        c := &w.Corpi[idx]
+        if c.Immaterial {
+            continue
+        }
+        d := c.Pos.Sub(origin)
+        b := d.Dot(u)
+        cc := d.MagSq() - c.Radius*c.Radius
+        if cc <= 0 || b <= 0 { // inside, or behind the origin
+            continue
+        }
+        disc := b*b - cc
+        if disc < 0 {
+            continue
+        }
+        if t := b - math.Sqrt(disc); t <= nearest {
+            hit, nearest = idx, t
+        }
+    }
+    if hit < 0 {
+        return 0, Vector{}, false
+    }
+    return w.ids[hit], origin.Add(u.Mult(nearest)), true
+}
+
+// Nearest returns the ID of the corpus whose center is closest to that of
+// the corpus with the given ID, immaterial ones included, the distance
+// between their centers, and false if there is no such pair.
+func (w *World) Nearest(id ID) (ID, float64, bool) {
+    self, ok := w.index(id)
+    if !ok {
+        return 0, 0, false
+    }
+    nearest, best := -1, math.Inf(1)
+    for idx := range w.Corpi {
+        if distSq := w.Corpi[idx].Pos.DistSq(w.Corpi[self].Pos); idx != self && distSq < best {
+            nearest, best = idx, distSq
+        }
+    }
+    if nearest < 0 {
+        return 0, 0, false
+    }
+    return w.ids[nearest], math.Sqrt(best), true
+}
+
+// NearestK returns the IDs of the k corpi whose centers are closest to that
+// of the corpus with the given ID, nearest first, or all of the others if
+// there are fewer. Ties keep the order of Corpi.
+func (w *World) NearestK(id ID, k int) []ID {
+    self, ok := w.index(id)
+    if !ok || k <= 0 {
+        return nil
+    }
+    others := make([]int, 0, len(w.Corpi)-1)
+    for idx := range w.Corpi {
+        if idx != self {
+            others = append(others, idx)
+        }
+    }
+    pos := w.Corpi[self].Pos
+    sort.SliceStable(others, func(i, j int) bool {
+        return w.Corpi[others[i]].Pos.DistSq(pos) < w.Corpi[others[j]].Pos.DistSq(pos)
+    })
+    if len(others) > k {
+        others = others[:k]
+    }
+    found := make([]ID, len(others))
+    for i, idx := range others {
+        found[i] = w.ids[idx]
+    }
+    return found
+}
+
+// Invalidate discards the spatial index the world keeps for queries
+// between steps. It must be called before the next query after moving
+// corpi other than with Step, or after replacing the Broadphase.
+func (w *World) Invalidate() {
+    w.query = nil
+}
+
+// candidates returns the indices of the corpi whose bounding boxes overlap
+// the box from min to max, in increasing order. It uses an index of the
+// broadphase if it is a Querier, built once until the world changes.
+func (w *World) candidates(min, max Vector) []int {
+    w.sync()
+    if q, ok := w.Broadphase.(Querier); ok {
+        if w.query == nil {
+            w.query = q.Index(w.Corpi)
+        }
+        return w.query.Query(min, max)
+    }
+
+    var found []int
+    for idx := range w.Corpi {
+        cMin, cMax := w.Corpi[idx].Bounds()
+        if boxesOverlap(min, max, cMin, cMax) {
+            found = append(found, idx)
+        }
+    }
+    return found
+}
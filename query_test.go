@@ -0,0 +1,192 @@
+package corpus
+
+import (
+	"math"
+	"testing"
+)
+
+// queryWorlds returns worlds holding the same corpi, searched linearly,
+// with a SpatialHash and with a Quadtree.
+func queryWorlds(corpi ...Corpus) []*World {
+	var worlds []*World
+	for _, bp := range []Broadphase{nil, &SpatialHash{CellSize: 2}, &Quadtree{Capacity: 1}} {
+		w := NewWorld(0, 0)
+		w.Broadphase = bp
+		for _, c := range corpi {
+			w.Add(c)
+		}
+		worlds = append(worlds, w)
+	}
+	return worlds
+}
+
+// sameIDs reports whether the two slices hold the same IDs in order.
+func sameIDs(a, b []ID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWorld_QueryRadius(t *testing.T) {
+	for _, w := range queryWorlds(
+		Corpus{Pos: Vector{10, 0}, Mass: 1, Radius: 1},         // center at 10
+		Corpus{Pos: Vector{0, 10.001}, Mass: 1, Radius: 1},     // center just outside
+		Corpus{Pos: Vector{-9.999, 0}, Mass: 1, Radius: 1},     // center just inside
+		Corpus{Pos: Vector{0, -10.999}, Mass: 1, Radius: 1},    // body just inside
+		Corpus{Pos: Vector{11.001, 0}, Mass: 1, Radius: 1},     // body just outside
+		Corpus{Pos: Vector{0, 0}, Radius: 1, Immaterial: true}, // immaterial
+		Corpus{Pos: Vector{50, 50}, Mass: 1, Radius: 1},        // far away
+	) {
+		if !sameIDs(w.QueryRadius(Vector{0, 0}, 10), []ID{1, 3, 6}) {
+			t.Error("WRONG !!")
+		}
+
+		w.QueryMode = QueryOverlap
+
+		if !sameIDs(w.QueryRadius(Vector{0, 0}, 10), []ID{1, 2, 3, 4, 6}) {
+			t.Error("WRONG !!")
+		}
+
+		// Moved corpi are found once the index is discarded.
+		w.Corpi[6].Pos = Vector{5, 5}
+		w.Invalidate()
+
+		if !sameIDs(w.QueryRadius(Vector{0, 0}, 10), []ID{1, 2, 3, 4, 6, 7}) {
+			t.Error("WRONG !!")
+		}
+
+		w.Remove(1)
+
+		if !sameIDs(w.QueryRadius(Vector{10, 0}, 0), nil) {
+			t.Error("WRONG !!")
+		}
+	}
+
+	if NewWorld(0, 0).QueryRadius(Vector{0, 0}, 10) != nil {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_QueryRect(t *testing.T) {
+	for _, w := range queryWorlds(
+		Corpus{Pos: Vector{5, 5}, Mass: 1, Radius: 1},           // inside
+		Corpus{Pos: Vector{10.5, 5}, Mass: 1, Radius: 1},        // straddling the edge
+		Corpus{Pos: Vector{10, 0}, Mass: 1, Radius: 1},          // center on the corner
+		Corpus{Pos: Vector{10.8, 10.8}, Mass: 1, Radius: 1},     // off the corner
+		Corpus{Pos: Vector{5, -2}, Radius: 1, Immaterial: true}, // immaterial
+	) {
+		// The corners may be given in any order.
+		if !sameIDs(w.QueryRect(Vector{0, 10}, Vector{10, 0}), []ID{1, 3}) {
+			t.Error("WRONG !!")
+		}
+
+		w.QueryMode = QueryOverlap
+
+		if !sameIDs(w.QueryRect(Vector{0, 0}, Vector{10, 10}), []ID{1, 2, 3}) {
+			t.Error("WRONG !!")
+		}
+
+		if !sameIDs(w.QueryRect(Vector{0, -5}, Vector{10, -1}), []ID{3, 5}) {
+			t.Error("WRONG !!")
+		}
+	}
+}
+
+func TestWorld_Raycast(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(Corpus{Pos: Vector{10, 2}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{10, -2}, Mass: 1, Radius: 1})
+	far := w.Add(Corpus{Pos: Vector{20, 0}, Mass: 1, Radius: 2})
+	w.Add(Corpus{Pos: Vector{15, 0}, Radius: 1, Immaterial: true})
+	self := w.Add(Corpus{Pos: Vector{0, 0}, Mass: 1, Radius: 1})
+
+	// Between the first two, through the immaterial one and out of
+	// the origin corpus.
+	id, p, ok := w.Raycast(Vector{0, 0}, Vector{1, 0}, 100)
+
+	if !ok || id != far || !p.Equals(Vector{18, 0}, Epsilon) || id == self {
+		t.Error("WRONG !!")
+	}
+
+	// Not far enough.
+	if _, _, ok := w.Raycast(Vector{0, 0}, Vector{1, 0}, 17.9); ok {
+		t.Error("WRONG !!")
+	}
+
+	// Straight at the first one, the direction need not be a unit vector.
+	id, p, ok = w.Raycast(Vector{10, 10}, Vector{0, -5}, 100)
+
+	if !ok || id != 1 || !p.Equals(Vector{10, 3}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	// Away from everything.
+	if _, _, ok := w.Raycast(Vector{0, 0}, Vector{-1, 0}, 100); ok {
+		t.Error("WRONG !!")
+	}
+
+	if _, _, ok := w.Raycast(Vector{0, 0}, Vector{0, 0}, 100); ok {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Nearest(t *testing.T) {
+	w := NewWorld(0, 0)
+	left := w.Add(Corpus{Pos: Vector{0, 0}, Mass: 1, Radius: 1})
+	middle := w.Add(Corpus{Pos: Vector{5, 0}, Mass: 1, Radius: 1})
+	right := w.Add(Corpus{Pos: Vector{8, 0}, Mass: 1, Radius: 1})
+
+	id, dist, ok := w.Nearest(middle)
+
+	if !ok || id != right || math.Abs(dist-3) > Epsilon {
+		t.Error("WRONG !!")
+	}
+
+	id, dist, ok = w.Nearest(left)
+
+	if !ok || id != middle || math.Abs(dist-5) > Epsilon {
+		t.Error("WRONG !!")
+	}
+
+	w.Corpi[2].Pos = Vector{11, 0}
+	id, _, _ = w.Nearest(middle)
+
+	if id != left {
+		t.Error("WRONG !!")
+	}
+
+	if _, _, ok := w.Nearest(42); ok {
+		t.Error("WRONG !!")
+	}
+
+	lone := NewWorld(0, 0)
+
+	if _, _, ok := lone.Nearest(lone.Add(Corpus{})); ok {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_NearestK(t *testing.T) {
+	w := NewWorld(0, 0)
+	for _, x := range []float64{0, 5, 8, 1, -3} {
+		w.Add(Corpus{Pos: Vector{x, 0}, Mass: 1, Radius: 1})
+	}
+
+	if !sameIDs(w.NearestK(1, 2), []ID{4, 5}) {
+		t.Error("WRONG !!")
+	}
+
+	if !sameIDs(w.NearestK(2, 10), []ID{3, 4, 1, 5}) {
+		t.Error("WRONG !!")
+	}
+
+	if w.NearestK(1, 0) != nil || w.NearestK(42, 1) != nil {
+		t.Error("WRONG !!")
+	}
+}
@@ -0,0 +1,57 @@
+package corpus
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+)
+
+// WriteSVG draws the world as an SVG image of the given size: every corpus
+// as a circle filled with its color, with a line from its center along its
+// velocity if it moves, and dashed if it is immaterial. The image shows the
+// bounds of the world, or the box around its corpi if it is unbounded.
+func (w *World) WriteSVG(out io.Writer, width, height float64) error {
+    min, max := w.view()
+    bw := bufio.NewWriter(out)
+    fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" viewBox=\"%g %g %g %g\">\n",
+        width, height, min.X, min.Y, max.X-min.X, max.Y-min.Y)
+    for _, c := range w.Corpi {
+        dash := ""
+        if c.Immaterial {
+            dash = fmt.Sprintf(" stroke-dasharray=\"%g\"", c.Radius/4)
+        }
+        col := c.drawColor()
+        fmt.Fprintf(bw, "  <circle cx=\"%g\" cy=\"%g\" r=\"%g\" fill=\"#%02x%02x%02x\" fill-opacity=\"%g\" stroke=\"black\" stroke-width=\"%g\"%s/>\n",
+            c.Pos.X, c.Pos.Y, c.Radius, col.R, col.G, col.B, float64(col.A)/255, c.Radius/10, dash)
+        if !c.Vel.IsZero() {
+            end := c.Pos.Add(c.Vel)
+            fmt.Fprintf(bw, "  <line x1=\"%g\" y1=\"%g\" x2=\"%g\" y2=\"%g\" stroke=\"red\" stroke-width=\"%g\"/>\n",
+                c.Pos.X, c.Pos.Y, end.X, end.Y, c.Radius/10)
+        }
+    }
+    fmt.Fprintln(bw, "</svg>")
+    return bw.Flush()
+}
+
+// view returns the corners of the area of the world to draw: its bounds,
+// or the box around its corpi if it is unbounded.
+func (w *World) view() (min, max Vector) {
+    if w.Width != 0 && w.Height != 0 {
+        return Vector{0, 0}, Vector{w.Width, w.Height}
+    }
+    for idx := range w.Corpi {
+        cMin, cMax := w.Corpi[idx].Bounds()
+        if idx == 0 {
+            min, max = cMin, cMax
+        }
+        min, max = min.Min(cMin), max.Max(cMax)
+    }
+    // Corpi without extent along an axis still get a unit of it.
+    if max.X == min.X {
+        max.X = min.X + 1
+    }
+    if max.Y == min.Y {
+        max.Y = min.Y + 1
+    }
+    return min, max
+}
@@ -0,0 +1,72 @@
+package corpus
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWorld_WriteSVG(t *testing.T) {
+	w := NewWorld(100, 50)
+	w.Add(Corpus{Pos: Vector{10, 10}, Vel: Vector{3, 0}, Mass: 1, Radius: 2})
+	w.Add(Corpus{Pos: Vector{50, 20}, Mass: 1, Radius: 5})
+	w.Add(Corpus{Pos: Vector{80, 40}, Radius: 3, Immaterial: true})
+	var buf bytes.Buffer
+
+	if err := w.WriteSVG(&buf, 400, 200); err != nil {
+		t.Fatal(err)
+	}
+
+	circles, lines := 0, 0
+	d := xml.NewDecoder(&buf)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if el, ok := tok.(xml.StartElement); ok {
+			switch el.Name.Local {
+			case "circle":
+				circles++
+			case "line":
+				lines++
+			}
+		}
+	}
+
+	if circles != 3 || lines != 1 {
+		t.Error("WRONG !!")
+	}
+
+	buf.Reset()
+	w.WriteSVG(&buf, 400, 200)
+
+	if !strings.Contains(buf.String(), `viewBox="0 0 100 50"`) || strings.Count(buf.String(), "stroke-dasharray") != 1 {
+		t.Error("WRONG !!")
+	}
+
+	// An unbounded world shows its corpi.
+	w.Width = 0
+	buf.Reset()
+	w.WriteSVG(&buf, 400, 200)
+
+	if !strings.Contains(buf.String(), `viewBox="8 8 75 35"`) {
+		t.Error("WRONG !!")
+	}
+
+	// Corpi lined up without extent along an axis still get some.
+	w = NewWorld(0, 0)
+	w.Add(Corpus{Pos: Vector{5, 0}})
+	w.Add(Corpus{Pos: Vector{5, 10}})
+	buf.Reset()
+	w.WriteSVG(&buf, 400, 200)
+
+	if !strings.Contains(buf.String(), `viewBox="5 0 1 10"`) {
+		t.Error("WRONG !!")
+	}
+}
@@ -0,0 +1,421 @@
+package corpus
+
+import (
+    "fmt"
+    "math"
+)
+
+// Float is the constraint satisfied by the floating point types
+// a Vec can have as components.
+type Float interface {
+    ~float32 | ~float64
+}
+
+// Vec is a 2D vector generic over the precision of its components, so a
+// single implementation serves every precision: Vector and Vector64 are
+// the float64 Vec, Vector32 the float32 one. The math functions are
+// computed in float64 and rounded to T.
+type Vec[T Float] struct {
+    X, Y T
+}
+
+// Vector64 is the float64 Vec, another name for Vector.
+type Vector64 = Vec[float64]
+
+// Abs returns a new vector with the absolute values of the components.
+func (a Vec[T]) Abs() Vec[T] {
+    return Vec[T]{abs(a.X), abs(a.Y)}
+}
+
+// Add adds two vectors, returning a new vector.
+func (a Vec[T]) Add(b Vec[T]) Vec[T] {
+    return Vec[T]{a.X + b.X, a.Y + b.Y}
+}
+
+// AddI adds two vectors in place.
+func (a *Vec[T]) AddP(b Vec[T]) {
+    a.X = a.X + b.X
+    a.Y = a.Y + b.Y
+}
+
+// Angle returns the heading of the vector relative to the positive
+// X axis in radians, in the range (-Pi, Pi].
+func (a Vec[T]) Angle() T {
+    // Atan2 yields -Pi for a negative zero Y.
+    return T(WrapAngle(math.Atan2(float64(a.Y), float64(a.X))))
+}
+
+// AngleBetween returns the angle between the two vectors in radians.
+// The cosine is clamped into [-1, 1] so that rounding errors on parallel
+// vectors do not make Acos return NaN.
+func (a Vec[T]) AngleBetween(b Vec[T]) T {
+    cos := a.Dot(b) / (a.Mag() * b.Mag())
+    return T(math.Acos(Clamp(float64(cos), -1, 1)))
+}
+
+// Ceil rounds each component up to the nearest integer, returning a new vector.
+func (a Vec[T]) Ceil() Vec[T] {
+    return Vec[T]{T(math.Ceil(float64(a.X))), T(math.Ceil(float64(a.Y)))}
+}
+
+// ClampRect clamps each component of the vector into the rectangle
+// spanned by min and max, returning a new vector.
+func (a Vec[T]) ClampRect(min, max Vec[T]) Vec[T] {
+    return a.Max(min).Min(max)
+}
+
+// Complex returns the vector as the complex number x + yi,
+// for use with math/cmplx.
+func (a Vec[T]) Complex() complex128 {
+    return complex(float64(a.X), float64(a.Y))
+}
+
+// Cross returns the z component of the 3D cross product of the two
+// vectors, x1*y2 - y1*x2. It is positive when b is counter-clockwise of a.
+func (a Vec[T]) Cross(b Vec[T]) T {
+    return a.X*b.Y - a.Y*b.X
+}
+
+// DirectionTo returns the unit vector pointing from a to b,
+// or the zero vector if a and b are equal.
+func (a Vec[T]) DirectionTo(b Vec[T]) Vec[T] {
+    return b.Sub(a).Norm()
+}
+
+// Dist returns the distance between the two vectors.
+func (a Vec[T]) Dist(b Vec[T]) T {
+    return a.Sub(b).Mag()
+}
+
+// DistChebyshev returns the Chebyshev (chessboard) distance between the
+// two vectors, max(|dx|, |dy|). It complements Dist and DistSq.
+func (a Vec[T]) DistChebyshev(b Vec[T]) T {
+    d := a.Sub(b).Abs()
+    return max(d.X, d.Y)
+}
+
+// DistManhattan returns the Manhattan (taxicab) distance between the
+// two vectors, |dx| + |dy|. It complements Dist and DistSq.
+func (a Vec[T]) DistManhattan(b Vec[T]) T {
+    d := a.Sub(b).Abs()
+    return d.X + d.Y
+}
+
+// DistSq returns the distance between the two vectors, squared.
+func (a Vec[T]) DistSq(b Vec[T]) T {
+    return a.Sub(b).MagSq()
+}
+
+// DistToSegment returns the shortest distance from the point to the
+// line segment between start and end. If start and end coincide it is
+// the distance to that point.
+func (a Vec[T]) DistToSegment(start, end Vec[T]) T {
+    d := end.Sub(start)
+    lenSq := d.MagSq()
+    if lenSq == 0 {
+        return a.Dist(start)
+    }
+    t := T(Clamp(float64(a.Sub(start).Dot(d)/lenSq), 0, 1))
+    return a.Dist(start.Add(d.Mult(t)))
+}
+
+// Div divides the vector with a scalar, returning a new vector.
+func (a Vec[T]) Div(b T) Vec[T] {
+    return Vec[T]{a.X / b, a.Y / b}
+}
+
+// DivP divides the vector with a scalar in place.
+func (a *Vec[T]) DivP(b T) {
+    a.X = a.X / b
+    a.Y = a.Y / b
+}
+
+// Dot returns the dot product of two vectors.
+func (a Vec[T]) Dot(b Vec[T]) T {
+    return a.X*b.X + a.Y*b.Y
+}
+
+// Equals reports whether both components of the two vectors are
+// within eps of each other. Epsilon is a sensible default for eps, or
+// Epsilon32 for a Vector32.
+func (a Vec[T]) Equals(b Vec[T], eps T) bool {
+    return abs(a.X-b.X) <= eps && abs(a.Y-b.Y) <= eps
+}
+
+// Floor rounds each component down to the nearest integer, returning a new vector.
+func (a Vec[T]) Floor() Vec[T] {
+    return Vec[T]{T(math.Floor(float64(a.X))), T(math.Floor(float64(a.Y)))}
+}
+
+// IsValid reports whether neither component of the vector is NaN or infinite.
+func (a Vec[T]) IsValid() bool {
+    x, y := float64(a.X), float64(a.Y)
+    return !math.IsNaN(x) && !math.IsNaN(y) && !math.IsInf(x, 0) && !math.IsInf(y, 0)
+}
+
+// IsZero reports whether both components of the vector are exactly 0.
+func (a Vec[T]) IsZero() bool {
+    return a.X == 0 && a.Y == 0
+}
+
+// Lerp linearly interpolates between a and b by t, returning a new vector.
+// t is not clamped, so values outside [0, 1] extrapolate.
+func (a Vec[T]) Lerp(b Vec[T], t T) Vec[T] {
+    return a.Add(b.Sub(a).Mult(t))
+}
+
+// LerpClamped is like Lerp but clamps t into [0, 1].
+func (a Vec[T]) LerpClamped(b Vec[T], t T) Vec[T] {
+    return a.Lerp(b, T(Clamp(float64(t), 0, 1)))
+}
+
+// Limit returns the vector unchanged if its magnitude is at most max,
+// otherwise a new vector in the same direction with magnitude max.
+// A negative max is taken as 0, it never reverses the vector.
+func (a Vec[T]) Limit(max T) Vec[T] {
+    if max < 0 {
+        max = 0
+    }
+    if a.MagSq() > max*max {
+        return a.SetMag(max)
+    }
+    return a
+}
+
+// LimitP caps the magnitude of the vector at max in place, like Limit.
+func (a *Vec[T]) LimitP(max T) {
+    if max < 0 {
+        max = 0
+    }
+    if a.MagSq() > max*max {
+        a.SetMagP(max)
+    }
+}
+
+// Mag returns the magnitude of the vector
+func (a Vec[T]) Mag() T {
+    return sqrt(a.X*a.X + a.Y*a.Y)
+}
+
+// MagSq returns the magnitude of the vector, squared.
+func (a Vec[T]) MagSq() T {
+    return a.X*a.X + a.Y*a.Y
+}
+
+// Max returns the componentwise maximum of the two vectors.
+func (a Vec[T]) Max(b Vec[T]) Vec[T] {
+    return Vec[T]{max(a.X, b.X), max(a.Y, b.Y)}
+}
+
+// Midpoint returns the point halfway between the two vectors.
+func (a Vec[T]) Midpoint(b Vec[T]) Vec[T] {
+    return a.Add(b).Div(2)
+}
+
+// Min returns the componentwise minimum of the two vectors.
+func (a Vec[T]) Min(b Vec[T]) Vec[T] {
+    return Vec[T]{min(a.X, b.X), min(a.Y, b.Y)}
+}
+
+// MoveTowards returns a moved towards target by at most maxDelta,
+// snapping exactly to target when it is within maxDelta.
+func (a Vec[T]) MoveTowards(target Vec[T], maxDelta T) Vec[T] {
+    d := target.Sub(a)
+    dist := d.Mag()
+    if dist <= maxDelta || dist == 0 {
+        return target
+    }
+    return a.Add(d.Mult(maxDelta / dist))
+}
+
+// Mult multiplies the vector with a scalar, returning a new vector.
+func (a Vec[T]) Mult(b T) Vec[T] {
+    return Vec[T]{a.X * b, a.Y * b}
+}
+
+// MultP multiplies the vector with a scalar in place.
+func (a *Vec[T]) MultP(b T) {
+    a.X = a.X * b
+    a.Y = a.Y * b
+}
+
+// Neg returns the negated vector {-x, -y}.
+func (a Vec[T]) Neg() Vec[T] {
+    return Vec[T]{-a.X, -a.Y}
+}
+
+// Norm normalizes a vector, returning a new vector.
+// The zero vector has no direction and is returned as the zero vector.
+func (a Vec[T]) Norm() Vec[T] {
+    mag := a.Mag()
+    if mag == 0 {
+        return Vec[T]{0, 0}
+    }
+    return a.Div(mag)
+}
+
+// NormP normalizes a vector in place.
+// The zero vector is left unchanged.
+func (a *Vec[T]) NormP() {
+    mag := a.Mag()
+    if mag != 0 {
+        a.DivP(mag)
+    }
+}
+
+// Perp returns the left-hand perpendicular {-y, x} of the vector,
+// i.e. the vector rotated 90 degrees counter-clockwise.
+func (a Vec[T]) Perp() Vec[T] {
+    return Vec[T]{-a.Y, a.X}
+}
+
+// PerpCW returns the right-hand perpendicular {y, -x} of the vector,
+// i.e. the vector rotated 90 degrees clockwise.
+func (a Vec[T]) PerpCW() Vec[T] {
+    return Vec[T]{a.Y, -a.X}
+}
+
+// Project returns the component of the vector along onto.
+// Returns the zero vector if onto has zero length.
+func (a Vec[T]) Project(onto Vec[T]) Vec[T] {
+    magSq := onto.MagSq()
+    if magSq == 0 {
+        return Vec[T]{0, 0}
+    }
+    return onto.Mult(a.Dot(onto) / magSq)
+}
+
+// Reflect reflects the vector about the given surface normal,
+// returning a new vector. The normal does not need to be normalized.
+// Uses r = a - 2*<a, n>*n for the unit normal n.
+func (a Vec[T]) Reflect(normal Vec[T]) Vec[T] {
+    n := normal.Norm()
+    return a.Sub(n.Mult(2 * a.Dot(n)))
+}
+
+// Reject returns the component of the vector orthogonal to onto,
+// so that a.Project(onto) + a.Reject(onto) == a.
+// Returns the vector itself if onto has zero length.
+func (a Vec[T]) Reject(onto Vec[T]) Vec[T] {
+    return a.Sub(a.Project(onto))
+}
+
+// Rotate rotates the vector counter-clockwise by theta radians
+// around the origin, returning a new vector.
+func (a Vec[T]) Rotate(theta T) Vec[T] {
+    sin, cos := math.Sincos(float64(theta))
+    return Vec[T]{a.X*T(cos) - a.Y*T(sin), a.X*T(sin) + a.Y*T(cos)}
+}
+
+// RotateAround rotates the vector counter-clockwise by theta radians
+// around the given pivot, returning a new vector.
+func (a Vec[T]) RotateAround(pivot Vec[T], theta T) Vec[T] {
+    return a.Sub(pivot).Rotate(theta).Add(pivot)
+}
+
+// RotateP rotates the vector counter-clockwise by theta radians
+// around the origin in place.
+func (a *Vec[T]) RotateP(theta T) {
+    *a = a.Rotate(theta)
+}
+
+// Round rounds each component to the nearest integer, rounding half away
+// from zero, returning a new vector.
+func (a Vec[T]) Round() Vec[T] {
+    return Vec[T]{round(a.X), round(a.Y)}
+}
+
+// Scale multiplies the two vectors componentwise, returning a new vector.
+func (a Vec[T]) Scale(b Vec[T]) Vec[T] {
+    return Vec[T]{a.X * b.X, a.Y * b.Y}
+}
+
+// ScaleDiv divides a by b componentwise, returning a new vector.
+// A zero component of b yields 0 for that axis instead of dividing by zero.
+func (a Vec[T]) ScaleDiv(b Vec[T]) Vec[T] {
+    res := Vec[T]{0, 0}
+    if b.X != 0 {
+        res.X = a.X / b.X
+    }
+    if b.Y != 0 {
+        res.Y = a.Y / b.Y
+    }
+    return res
+}
+
+// SetMag returns a new vector in the same direction with given magnitude.
+func (a Vec[T]) SetMag(mag T) Vec[T] {
+    return a.Norm().Mult(mag)
+}
+
+// SetMagP mutates the magnitude of the vector, keeping the direction.
+func (a *Vec[T]) SetMagP(mag T) {
+    a.NormP()
+    a.MultP(mag)
+}
+
+// SignedAngle returns the angle from a to b in radians, in the range
+// (-Pi, Pi]. It is positive when b is counter-clockwise of a.
+func (a Vec[T]) SignedAngle(b Vec[T]) T {
+    return T(WrapAngle(math.Atan2(float64(a.Cross(b)), float64(a.Dot(b)))))
+}
+
+// Slice returns the vector as the slice []T{x, y}.
+func (a Vec[T]) Slice() []T {
+    return []T{a.X, a.Y}
+}
+
+// Snap rounds each component to the nearest multiple of cell,
+// returning a new vector. A cell of 0 returns the vector unchanged.
+func (a Vec[T]) Snap(cell T) Vec[T] {
+    if cell == 0 {
+        return a
+    }
+    return Vec[T]{round(a.X/cell) * cell, round(a.Y/cell) * cell}
+}
+
+// String returns the vector in the stable form "(x, y)"
+// with three decimal places.
+func (a Vec[T]) String() string {
+    return fmt.Sprintf("(%.3f, %.3f)", a.X, a.Y)
+}
+
+// Sub subtracts a from b, returning a new vector.
+func (a Vec[T]) Sub(b Vec[T]) Vec[T] {
+    return Vec[T]{a.X - b.X, a.Y - b.Y}
+}
+
+// SubP subtracts a from b in place.
+func (a *Vec[T]) SubP(b Vec[T]) {
+    a.X = a.X - b.X
+    a.Y = a.Y - b.Y
+}
+
+// Vector returns the vector with float64 components.
+func (a Vec[T]) Vector() Vector {
+    return Vector{float64(a.X), float64(a.Y)}
+}
+
+// Vector32 returns the vector with float32 components.
+func (a Vec[T]) Vector32() Vector32 {
+    return Vector32{float32(a.X), float32(a.Y)}
+}
+
+// WeightedMid returns the point a fraction t of the way from a to b,
+// so t = 0.5 gives the Midpoint. It is Lerp named for weighted centers.
+func (a Vec[T]) WeightedMid(b Vec[T], t T) Vec[T] {
+    return a.Lerp(b, t)
+}
+
+// abs, round and sqrt compute the math functions of any Float in float64.
+func abs[T Float](x T) T {
+    return T(math.Abs(float64(x)))
+}
+
+func round[T Float](x T) T {
+    return T(math.Round(float64(x)))
+}
+
+func sqrt[T Float](x T) T {
+    return T(math.Sqrt(float64(x)))
+}
@@ -0,0 +1,60 @@
+package corpus
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVec_Add(t *testing.T) {
+	if (Vector64{1, 2}).Add(Vector64{3, -4}) != (Vector64{4, -2}) {
+		t.Error("WRONG !!")
+	}
+
+	if (Vec[float32]{1, 2}).Add(Vec[float32]{3, -4}) != (Vec[float32]{4, -2}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVec_AngleBetween(t *testing.T) {
+	if math.Abs((Vector64{1, 0}).AngleBetween(Vector64{0, 2})-math.Pi/2) > Epsilon {
+		t.Error("WRONG !!")
+	}
+
+	// Parallel vectors must not make Acos return NaN.
+	if (Vec[float32]{0.1, 0.3}).AngleBetween(Vec[float32]{0.2, 0.6}) != 0 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVec_Mag(t *testing.T) {
+	if (Vector64{3, 4}).Mag() != 5 || (Vec[float32]{3, 4}).Mag() != 5 {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector64{0, 0}).Mag() != 0 || (Vec[float32]{0, 0}).Mag() != 0 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVec_Norm(t *testing.T) {
+	if !(Vector64{3, 4}).Norm().Equals(Vector64{0.6, 0.8}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	if !(Vec[float32]{3, 4}).Norm().Equals(Vec[float32]{0.6, 0.8}, 1e-6) {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector64{0, 0}).Norm() != (Vector64{0, 0}) || (Vec[float32]{0, 0}).Norm() != (Vec[float32]{0, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVec_Vector(t *testing.T) {
+	// Vector, Vector64 and Vec[float64] are one type.
+	var a Vector = Vector64{1.5, -2}
+
+	if (Vector32{1.5, -2}).Vector() != a || a.Vector32() != (Vector32{1.5, -2}) {
+		t.Error("WRONG !!")
+	}
+}
@@ -0,0 +1,10 @@
+package corpus
+
+// Epsilon32 is the tolerance of comparisons between Vector32 values, as
+// Epsilon is far below the resolution of float32.
+const Epsilon32 = 1e-5
+
+// Vector32 is the float32 Vec, halving the memory of large numbers of
+// vectors at the cost of precision. It has the same methods as Vector,
+// with float32 scalars, so the two can be swapped.
+type Vector32 = Vec[float32]
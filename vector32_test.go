@@ -0,0 +1,104 @@
+package corpus
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestVector32_Add(t *testing.T) {
+	a := Vector32{1, 2}
+	b := Vector32{3, -4}
+
+	if a.Add(b) != (Vector32{4, -2}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector32_Angle(t *testing.T) {
+	if (Vector32{-1, float32(math.Copysign(0, -1))}).Angle() != math.Pi || (Vector32{0, 2}).Angle() != math.Pi/2 {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector32{1, 0}).SignedAngle(Vector32{-1, 0}) != math.Pi || (Vector32{1, 0}).SignedAngle(Vector32{0, -1}) != -math.Pi/2 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector32_Mag(t *testing.T) {
+	a := Vector32{3, 4}
+
+	if a.Mag() != 5 {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector32{0, 0}).Mag() != 0 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector32_Mult(t *testing.T) {
+	a := Vector32{1, -2}
+
+	if a.Mult(2.5) != (Vector32{2.5, -5}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector32_Norm(t *testing.T) {
+	a := Vector32{3, 4}
+
+	if !a.Norm().Equals(Vector32{0.6, 0.8}, Epsilon32) {
+		t.Error("WRONG !!")
+	}
+
+	if (Vector32{0, 0}).Norm() != (Vector32{0, 0}) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestVector32_Vector(t *testing.T) {
+	a := Vector{1.5, -2}
+
+	if a.Vector32().Vector() != a {
+		t.Error("WRONG !!")
+	}
+}
+
+// The vectors are far larger than the caches, so the sums are bound by
+// memory bandwidth, which Vector32 halves.
+const benchmarkVectors = 1 << 22
+
+func BenchmarkVector_Add_Large(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	vs := make([]Vector, benchmarkVectors)
+	for idx := range vs {
+		vs[idx] = Vector{r.Float64(), r.Float64()}
+	}
+	b.SetBytes(int64(len(vs)) * 16)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sum := Vector{0, 0}
+		for _, v := range vs {
+			sum.AddP(v)
+		}
+	}
+}
+
+func BenchmarkVector32_Add_Large(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	vs := make([]Vector32, benchmarkVectors)
+	for idx := range vs {
+		vs[idx] = Vector32{r.Float32(), r.Float32()}
+	}
+	b.SetBytes(int64(len(vs)) * 8)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sum := Vector32{0, 0}
+		for _, v := range vs {
+			sum.AddP(v)
+		}
+	}
+}
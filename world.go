@@ -0,0 +1,378 @@
+package corpus
+
+import (
+    "math"
+    "math/rand"
+    "sort"
+)
+
+// World is a system of corpi together with the constants of the forces
+// between them and the bounds they live in. Step advances them all through
+// time in the right order, making World the main entry point of the package.
+type World struct {
+    Corpi []Corpus
+    // G and K are the gravitational and Coulomb constants, 0 disables the force.
+    G, K float64
+    // Softening is the Plummer softening length of gravity.
+    Softening float64
+    // Restitution is the coefficient of restitution of collisions and bounces.
+    // NewWorld sets it to 1, perfectly elastic; 0 is perfectly inelastic.
+    Restitution float64
+    // Width and Height are the window boundaries the corpi bounce off.
+    // The world is unbounded if either is 0.
+    Width, Height float64
+    // Broadphase finds the candidate pairs for collisions,
+    // every pair is tested if it is nil.
+    Broadphase Broadphase
+    // MergeOnContact makes touching material corpi that can collide merge
+    // into one, see Merge, instead of colliding.
+    MergeOnContact bool
+    // Corpi whose speed and acceleration stay at most SleepSpeed and
+    // SleepAcc for SleepSteps steps in a row fall asleep: they stop and
+    // are skipped by Step until a collision or Wake wakes them.
+    // Sleeping is disabled if SleepSteps is 0.
+    SleepSpeed, SleepAcc float64
+    SleepSteps           int
+    // Workers is the number of goroutines sharing the work of gravity and
+    // the electrostatic force in Step, which is done serially if it is at
+    // most 1. The results are the same either way, but as the workers
+    // compute the force within each pair twice, once for each corpus, it
+    // only pays off with more than 2 of them.
+    Workers int
+    // Rand is the source of randomness for the user of the world, such as
+    // for RandUnit or Fragment, so that a world seeded with Seed replays
+    // identically. The package never uses the global source.
+    Rand *rand.Rand
+    // FrameDelay is the delay between the frames of RecordGIF, in 100ths
+    // of a second, 4 if not positive.
+    FrameDelay int
+    // Generators are the force generators applied every Step.
+    Generators []ForceGenerator
+    // QueryMode selects whether spatial queries test centers or bodies.
+    QueryMode QueryMode
+
+    ids        []ID    // ids[i] identifies Corpi[i], in increasing order
+    lastID     ID
+    query      Index   // index of the broadphase for queries, nil if stale
+    accum      float64 // time passed to Advance but not stepped yet
+    iterations int     // collision passes per step, see SetSolverIterations
+
+    onCollision func(a, b ID, point Vector)
+}
+
+// ID is a stable handle to a corpus in a World. IDs are never reused,
+// so an ID stays valid until its corpus is removed whatever happens to
+// the others. The zero ID never refers to a corpus.
+type ID uint64
+
+// NewWorld initialises and returns an empty World with the given
+// boundaries and perfectly elastic collisions.
+func NewWorld(width, height float64) *World {
+    return &World{Restitution: 1, Width: width, Height: height}
+}
+
+// Seed makes the world draw its randomness from a new source with the
+// given seed.
+func (w *World) Seed(seed int64) {
+    w.Rand = rand.New(rand.NewSource(seed))
+}
+
+// Step advances the world as time dt passes. It applies gravity and the
+// electrostatic force between every pair of corpi once and the force
+// generators to every corpus, integrates them, removes the dead ones,
+// resolves collisions between every pair once per solver iteration and
+// bounces them off the boundaries, in that order.
+func (w *World) Step(dt float64) {
+    if w.Workers > 1 {
+        w.interactParallel()
+    } else {
+        if w.G != 0 {
+            GravitateAll(w.Corpi, w.G, w.Softening)
+        }
+        if w.K != 0 {
+            CoulombAll(w.Corpi, w.K)
+        }
+    }
+    w.generate()
+    for idx := range w.Corpi {
+        w.integrate(&w.Corpi[idx], dt)
+    }
+    w.removeDead()
+    w.collide()
+    if w.Width != 0 && w.Height != 0 {
+        for idx := range w.Corpi {
+            w.Corpi[idx].BounceRestitution(w.Width, w.Height, w.Restitution)
+        }
+    }
+    w.Invalidate()
+}
+
+// maxAdvanceSteps is the most steps Advance takes in one call.
+const maxAdvanceSteps = 16
+
+// Advance advances the world as real time realDt passes in steps of the
+// fixed fixedDt, so that the simulation does not depend on the frame rate.
+// It steps as many times as fit into the time passed so far, carrying the
+// remainder over to the next call. Returns the fraction of a step left
+// over, in [0, 1), to interpolate between the last two states with when
+// rendering. Nothing happens if fixedDt is not positive, and no time
+// passes if realDt is not positive.
+//
+// At most maxAdvanceSteps steps are taken per call, the time beyond them
+// is dropped: otherwise a frame slower than its steps would leave more time
+// for the next one, and the simulation would fall ever further behind.
+func (w *World) Advance(realDt, fixedDt float64) float64 {
+    if fixedDt <= 0 {
+        return 0
+    }
+    if realDt > 0 {
+        w.accum += realDt
+    }
+    for steps := 0; w.accum >= fixedDt; steps++ {
+        if steps == maxAdvanceSteps {
+            w.accum = math.Mod(w.accum, fixedDt)
+            break
+        }
+        w.Step(fixedDt)
+        w.accum -= fixedDt
+    }
+    return w.accum / fixedDt
+}
+
+// integrate updates the corpus as time dt passes, unless it is asleep,
+// and puts it to sleep once it has rested long enough.
+func (w *World) integrate(c *Corpus, dt float64) {
+    c.ApplyNetForce()
+    if c.asleep {
+        c.Acc = Vector{0, 0}
+        c.age(dt)
+        return
+    }
+    if w.SleepSteps > 0 && c.Vel.Mag() <= w.SleepSpeed && c.Acc.Mag() <= w.SleepAcc {
+        c.idle++
+    } else {
+        c.idle = 0
+    }
+    c.UpdateDt(dt)
+    if w.SleepSteps > 0 && c.idle >= w.SleepSteps {
+        c.asleep = true
+        c.Vel = Vector{0, 0}
+    }
+}
+
+// removeDead removes the corpi whose life ran out, see SetLife.
+func (w *World) removeDead() {
+    w.sync()
+    for idx := len(w.Corpi) - 1; idx >= 0; idx-- {
+        if w.Corpi[idx].IsDead() {
+            w.removeAt(idx)
+        }
+    }
+}
+
+// SetBroadphase makes the world find collisions with a SpatialHash of the
+// given cell size, or by testing every pair if cellSize is not positive.
+func (w *World) SetBroadphase(cellSize float64) {
+    if cellSize > 0 {
+        w.Broadphase = &SpatialHash{CellSize: cellSize}
+    } else {
+        w.Broadphase = nil
+    }
+    w.Invalidate()
+}
+
+// SetSolverIterations makes Step resolve the collisions between the corpi
+// n times per step rather than once. Resolving a pair can push a corpus
+// into another, so stacks of corpi pressing on each other need more
+// iterations not to sink into one another. It is 1 if n is below 1.
+func (w *World) SetSolverIterations(n int) {
+    w.iterations = max(n, 1)
+}
+
+// OnCollision sets the function called during Step for every pair of corpi
+// that collided, once per pair and step, with the point where they touch
+// once resolved. A nil function stops the calls. Merging corpi do not
+// collide.
+func (w *World) OnCollision(f func(a, b ID, point Vector)) {
+    w.onCollision = f
+}
+
+// collide resolves collisions between the corpi once per colliding pair
+// and solver iteration, testing only the candidate pairs of the broadphase
+// if there is one, and then reports them to the collision callback.
+func (w *World) collide() {
+    w.sync()
+    if w.MergeOnContact {
+        w.merge()
+        return
+    }
+    var hits []Pair
+    var reported map[Pair]bool
+    if w.onCollision != nil {
+        reported = make(map[Pair]bool)
+    }
+    resolve := func(i, j int) {
+        if w.collidePair(i, j) && w.onCollision != nil && !reported[Pair{i, j}] {
+            reported[Pair{i, j}] = true
+            hits = append(hits, Pair{i, j})
+        }
+    }
+    for it := 0; it < max(w.iterations, 1); it++ {
+        if w.Broadphase == nil {
+            for i := range w.Corpi {
+                for j := i + 1; j < len(w.Corpi); j++ {
+                    resolve(i, j)
+                }
+            }
+            continue
+        }
+        for _, p := range w.Broadphase.Pairs(w.Corpi) {
+            resolve(p.A, p.B)
+        }
+    }
+    for _, p := range hits {
+        a, b := &w.Corpi[p.A], &w.Corpi[p.B]
+        w.onCollision(w.ids[p.A], w.ids[p.B], a.ClosestSurfacePoint(b.Pos))
+    }
+}
+
+// collidePair collides the corpi at indices i and j, returning whether
+// they collided. Two sleeping corpi are left alone, and a sleeping corpus
+// is woken by a collision with a moving one. One coming to rest too does
+// not wake it but is resolved against it as if it were static, so that
+// the sleeper is never moved while Step skips it.
+func (w *World) collidePair(i, j int) bool {
+    a, b := &w.Corpi[i], &w.Corpi[j]
+    if a.asleep && b.asleep {
+        return false
+    }
+    wakeA, wakeB := a.asleep && b.idle == 0, b.asleep && a.idle == 0
+    staticA, staticB := a.Static, b.Static
+    a.Static = staticA || a.asleep && !wakeA
+    b.Static = staticB || b.asleep && !wakeB
+    hit := a.collide(b, w.Restitution)
+    a.Static, b.Static = staticA, staticB
+    if !hit {
+        return false
+    }
+    if wakeA {
+        a.Wake()
+    }
+    if wakeB {
+        b.Wake()
+    }
+    return true
+}
+
+// merge merges every pair of touching material corpi that can collide,
+// repeatedly until no two do. The merged corpus takes the place and ID of
+// the first of the pair, the second is removed.
+func (w *World) merge() {
+    w.sync()
+    // The merged corpus grows, so it may touch corpi passed over before,
+    // on either side of it: pass over them all again after any merge.
+    for merged := true; merged; {
+        merged = false
+        for i := 0; i < len(w.Corpi); i++ {
+            for j := i + 1; j < len(w.Corpi); j++ {
+                a, b := &w.Corpi[i], &w.Corpi[j]
+                if a.Immaterial || b.Immaterial || !a.CanCollide(b) || !a.IsInter(b) {
+                    continue
+                }
+                *a = Merge(*a, *b)
+                w.removeAt(j)
+                merged = true
+                j = i
+            }
+        }
+    }
+}
+
+// TotalKineticEnergy returns the sum of the kinetic energies of all
+// material corpi.
+func (w *World) TotalKineticEnergy() float64 {
+    e := 0.0
+    for _, c := range w.Corpi {
+        if !c.Immaterial {
+            e += c.KineticEnergy()
+        }
+    }
+    return e
+}
+
+// TotalMomentum returns the sum of the momenta of all material corpi.
+func (w *World) TotalMomentum() Vector {
+    p := Vector{0, 0}
+    for _, c := range w.Corpi {
+        if !c.Immaterial {
+            p.AddP(c.Momentum())
+        }
+    }
+    return p
+}
+
+// Add adds the corpus to the world and returns its ID.
+func (w *World) Add(c Corpus) ID {
+    w.sync()
+    w.lastID++
+    w.Corpi = append(w.Corpi, c)
+    w.ids = append(w.ids, w.lastID)
+    w.Invalidate()
+    return w.lastID
+}
+
+// Remove removes the corpus with the given ID from the world, keeping the
+// order of the rest. Returns false if there is no such corpus.
+func (w *World) Remove(id ID) bool {
+    idx, ok := w.index(id)
+    if !ok {
+        return false
+    }
+    w.removeAt(idx)
+    return true
+}
+
+// removeAt removes the corpus at the given index of Corpi.
+func (w *World) removeAt(idx int) {
+    w.Corpi = append(w.Corpi[:idx], w.Corpi[idx+1:]...)
+    w.ids = append(w.ids[:idx], w.ids[idx+1:]...)
+    w.Invalidate()
+}
+
+// Get returns a pointer to the corpus with the given ID, valid until
+// corpi are next added or removed, and whether there is such a corpus.
+func (w *World) Get(id ID) (*Corpus, bool) {
+    idx, ok := w.index(id)
+    if !ok {
+        return nil, false
+    }
+    return &w.Corpi[idx], true
+}
+
+// IDs returns the IDs of the corpi, in the order of Corpi.
+func (w *World) IDs() []ID {
+    w.sync()
+    return append([]ID(nil), w.ids...)
+}
+
+// index returns the index in Corpi of the corpus with the given ID.
+func (w *World) index(id ID) (int, bool) {
+    w.sync()
+    idx := sort.Search(len(w.ids), func(i int) bool { return w.ids[i] >= id })
+    return idx, idx < len(w.ids) && w.ids[idx] == id
+}
+
+// sync assigns IDs to any corpi appended to Corpi directly rather than
+// with Add. Corpi should only be removed with Remove.
+func (w *World) sync() {
+    if len(w.ids) != len(w.Corpi) {
+        w.Invalidate()
+    }
+    if len(w.ids) > len(w.Corpi) {
+        w.ids = w.ids[:len(w.Corpi)]
+    }
+    for len(w.ids) < len(w.Corpi) {
+        w.lastID++
+        w.ids = append(w.ids, w.lastID)
+    }
+}
@@ -0,0 +1,430 @@
+package corpus
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestNewWorld(t *testing.T) {
+	w := NewWorld(100, 50)
+
+	if w.Width != 100 || w.Height != 50 || w.Restitution != 1 || len(w.Corpi) != 0 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Seed(t *testing.T) {
+	// snapshot runs a random scenario drawn from the seed and returns the
+	// encoded corpi.
+	snapshot := func(seed int64) []byte {
+		w := NewWorld(200, 200)
+		w.Seed(seed)
+		w.SetBroadphase(4)
+		for i := 0; i < 50; i++ {
+			w.Add(Corpus{
+				Pos:    Vector{w.Rand.Float64() * 200, w.Rand.Float64() * 200},
+				Vel:    RandUnit(w.Rand).Mult(5),
+				Mass:   1 + w.Rand.Float64(),
+				Radius: 2,
+			})
+		}
+		for _, f := range w.Corpi[0].Fragment(5, 3, w.Rand) {
+			w.Add(f)
+		}
+		for i := 0; i < 100; i++ {
+			w.Step(0.1)
+		}
+		data, err := json.Marshal(w.Corpi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	if !bytes.Equal(snapshot(42), snapshot(42)) || bytes.Equal(snapshot(42), snapshot(43)) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Step(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.G = 1
+	w.Softening = 0.1
+	w.Corpi = []Corpus{
+		{Pos: Vector{0, 0}, Vel: Vector{0, -0.1}, Mass: 10, Radius: 1},
+		{Pos: Vector{10, 0}, Vel: Vector{0, 1}, Mass: 1, Radius: 0.5},
+	}
+	p := w.Corpi[0].Momentum().Add(w.Corpi[1].Momentum())
+
+	for i := 0; i < 1000; i++ {
+		w.Step(0.1)
+	}
+
+	for _, c := range w.Corpi {
+		if !c.Pos.IsValid() || !c.Vel.IsValid() || c.Pos.Mag() > 100 {
+			t.Error("WRONG !!")
+		}
+	}
+
+	res := w.Corpi[0].Momentum().Add(w.Corpi[1].Momentum())
+
+	if !res.Equals(p, 1e-6) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Step_Bounds(t *testing.T) {
+	w := NewWorld(10, 10)
+	w.Corpi = []Corpus{{Pos: Vector{5, 5}, Vel: Vector{3, 0}, Mass: 1, Radius: 1}}
+
+	for i := 0; i < 100; i++ {
+		w.Step(1)
+		c := w.Corpi[0]
+
+		if c.Pos.X < 1 || c.Pos.X > 9 {
+			t.Error("WRONG !!")
+		}
+	}
+}
+
+func TestWorld_Step_UserData(t *testing.T) {
+	type entity struct{ name string }
+	player := &entity{"player"}
+	w := NewWorld(100, 100)
+	id := w.Add(Corpus{Pos: Vector{50, 50}, Vel: Vector{1, 0}, Mass: 1, Radius: 1, UserData: player})
+	w.Add(Corpus{Pos: Vector{51, 50}, Mass: 1, Radius: 1})
+	w.Step(1)
+
+	if c, _ := w.Get(id); c.UserData != player || c.UserData.(*entity).name != "player" {
+		t.Error("WRONG !!")
+	}
+
+	if w.Corpi[1].UserData != nil {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Step_Life(t *testing.T) {
+	w := NewWorld(0, 0)
+	spark := Corpus{Pos: Vector{0, 0}, Radius: 1, Immaterial: true}
+	spark.SetLife(1)
+	w.Add(spark)
+	rock := w.Add(Corpus{Pos: Vector{10, 0}, Mass: 1, Radius: 1})
+	w.Step(0.5)
+
+	if len(w.Corpi) != 2 {
+		t.Error("WRONG !!")
+	}
+
+	w.Step(0.5)
+
+	if len(w.Corpi) != 1 || w.IDs()[0] != rock {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_OnCollision(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(Corpus{Pos: Vector{-20, 0}, Mass: 1, Radius: 1})
+	a := w.Add(Corpus{Pos: Vector{-1.5, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 1})
+	b := w.Add(Corpus{Pos: Vector{1.5, 0}, Vel: Vector{-1, 0}, Mass: 1, Radius: 1})
+	type event struct {
+		a, b  ID
+		point Vector
+	}
+	var events []event
+	w.OnCollision(func(a, b ID, point Vector) {
+		events = append(events, event{a, b, point})
+	})
+	w.Step(1)
+
+	if len(events) != 1 || events[0].a != a || events[0].b != b || !events[0].point.Equals(Vector{0, 0}, Epsilon) {
+		t.Error("WRONG !!")
+	}
+
+	// Separating afterwards.
+	w.Step(1)
+
+	if len(events) != 1 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Step_Sleep(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.SleepSpeed, w.SleepAcc, w.SleepSteps = 0.01, 0.01, 5
+	rest := w.Add(Corpus{Pos: Vector{0, 0}, Vel: Vector{0.001, 0}, Mass: 1, Radius: 1})
+	pile := w.Add(Corpus{Pos: Vector{2, 0}, Mass: 1, Radius: 1})
+	bullet := w.Add(Corpus{Pos: Vector{-20, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 1})
+	for i := 0; i < 5; i++ {
+		w.Step(1)
+	}
+	r, _ := w.Get(rest)
+	p, _ := w.Get(pile)
+	b, _ := w.Get(bullet)
+
+	// Touching corpi at rest sleep together, moving ones stay awake.
+	if !r.IsAsleep() || !p.IsAsleep() || b.IsAsleep() || !r.Vel.IsZero() {
+		t.Error("WRONG !!")
+	}
+
+	// Asleep corpi ignore forces.
+	r.ApplyForce(Vector{0, 1})
+	w.Step(1)
+
+	if r.Pos.Y != 0 || !r.Acc.IsZero() {
+		t.Error("WRONG !!")
+	}
+
+	// The bullet hits and wakes the corpi, passing its momentum down the
+	// line.
+	for i := 0; i < 15; i++ {
+		w.Step(1)
+	}
+
+	if r.IsAsleep() || p.IsAsleep() || p.Vel.X <= 0 {
+		t.Error("WRONG !!")
+	}
+
+	for i := 0; i < 5; i++ {
+		w.Step(1)
+	}
+	r.Wake()
+
+	if !b.IsAsleep() || r.IsAsleep() {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Step_Sleep_Resting(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(Corpus{Pos: Vector{0, 0}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{1.5, 0}, Vel: Vector{-0.001, 0}, Mass: 1, Radius: 1})
+	w.Corpi[0].asleep = true
+	w.Corpi[1].idle = 1
+
+	// A corpus coming to rest pushes off a sleeper without moving it.
+	if !w.collidePair(0, 1) {
+		t.Error("WRONG !!")
+	}
+
+	a, b := w.Corpi[0], w.Corpi[1]
+
+	if !a.IsAsleep() || a.Pos != (Vector{0, 0}) || !a.Vel.IsZero() || a.Static {
+		t.Error("WRONG !!")
+	}
+
+	if !b.Pos.Equals(Vector{2, 0}, Epsilon) || b.Vel.X <= 0 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Advance(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(MakeCorpus(0, 0, 1, 0, 1, 0, 1))
+
+	alpha := w.Advance(0.05, 0.016)
+
+	// 3 steps of 0.016 fit into 0.05, 0.002 is carried over.
+	if math.Abs(w.Corpi[0].Pos.X-0.048) > Epsilon || math.Abs(alpha-0.125) > Epsilon {
+		t.Error("WRONG !!")
+	}
+
+	// With the remainder 0.016 makes 1 step, with 0.002 left again.
+	alpha = w.Advance(0.016, 0.016)
+
+	if math.Abs(w.Corpi[0].Pos.X-0.064) > Epsilon || math.Abs(alpha-0.125) > Epsilon {
+		t.Error("WRONG !!")
+	}
+
+	if w.Advance(1, 0) != 0 || math.Abs(w.Corpi[0].Pos.X-0.064) > Epsilon {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Advance_NonPositive(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(MakeCorpus(0, 0, 1, 0, 1, 0, 1))
+	w.Advance(0.02, 0.016)
+
+	// Negative time does not eat into the 0.004 carried over.
+	alpha := w.Advance(-1, 0.016)
+
+	if math.Abs(w.Corpi[0].Pos.X-0.016) > Epsilon || math.Abs(alpha-0.25) > Epsilon {
+		t.Error("WRONG !!")
+	}
+
+	alpha = w.Advance(0.012, 0.016)
+
+	if math.Abs(w.Corpi[0].Pos.X-0.032) > Epsilon || math.Abs(alpha) > Epsilon {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Advance_MaxSteps(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(MakeCorpus(0, 0, 1, 0, 1, 0, 1))
+
+	// A 10 s hitch takes maxAdvanceSteps steps, the rest is dropped but
+	// for the fraction of a step.
+	alpha := w.Advance(10.008, 0.016)
+
+	if math.Abs(w.Corpi[0].Pos.X-0.016*maxAdvanceSteps) > Epsilon || math.Abs(alpha-0.5) > 1e-6 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_SetSolverIterations(t *testing.T) {
+	// overlap returns the deepest overlap of a stack of three corpi resting
+	// on a static floor after a step under gravity.
+	overlap := func(iterations int) float64 {
+		w := NewWorld(0, 0)
+		w.Restitution = 0
+		w.AddGenerator(UniformGravity{Vector{0, -10}})
+		w.Add(Corpus{Pos: Vector{0, -100}, Mass: 1, Radius: 100, Static: true})
+		for y := 1.0; y < 6; y += 2 {
+			w.Add(Corpus{Pos: Vector{0, y}, Mass: 1, Radius: 1})
+		}
+		w.SetSolverIterations(iterations)
+		w.Step(0.1)
+
+		deepest := 0.0
+		for i := range w.Corpi {
+			for j := i + 1; j < len(w.Corpi); j++ {
+				a, b := w.Corpi[i], w.Corpi[j]
+				deepest = math.Max(deepest, a.Radius+b.Radius-a.Pos.Dist(b.Pos))
+			}
+		}
+		return deepest
+	}
+
+	if overlap(1) < 1e-3 || overlap(0) != overlap(1) {
+		t.Error("WRONG !!")
+	}
+
+	if overlap(50) > 1e-6 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_TotalKineticEnergy(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(Corpus{Pos: Vector{0, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{2.5, 0.5}, Vel: Vector{-0.5, 0}, Mass: 2, Radius: 1})
+	w.Add(Corpus{Vel: Vector{10, 0}, Mass: 1, Immaterial: true})
+
+	if w.TotalKineticEnergy() != 0.75 {
+		t.Error("WRONG !!")
+	}
+
+	w.Step(1)
+
+	if math.Abs(w.TotalKineticEnergy()-0.75) > Epsilon {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_TotalMomentum(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.Add(Corpus{Pos: Vector{0, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{2.5, 0.5}, Vel: Vector{-0.5, 0}, Mass: 2, Radius: 1})
+	w.Add(Corpus{Vel: Vector{10, 0}, Mass: 1, Immaterial: true})
+	p := w.TotalMomentum()
+
+	if p != (Vector{0, 0}) {
+		t.Error("WRONG !!")
+	}
+
+	w.Step(1)
+
+	if !w.TotalMomentum().Equals(p, Epsilon) || w.Corpi[0].Vel.X >= 0 {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Add(t *testing.T) {
+	w := NewWorld(0, 0)
+	a := w.Add(Corpus{Mass: 1, Tag: "a"})
+	b := w.Add(Corpus{Mass: 2, Tag: "b"})
+
+	if a == b || a == 0 || b == 0 || len(w.Corpi) != 2 {
+		t.Error("WRONG !!")
+	}
+
+	// Corpi appended directly get IDs too.
+	w.Corpi = append(w.Corpi, Corpus{Mass: 3, Tag: "c"})
+	ids := w.IDs()
+
+	if len(ids) != 3 || ids[0] != a || ids[1] != b {
+		t.Error("WRONG !!")
+	}
+
+	if c, ok := w.Get(ids[2]); !ok || c.Tag != "c" {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_Remove(t *testing.T) {
+	w := NewWorld(0, 0)
+	a := w.Add(Corpus{Mass: 1, Tag: "a"})
+	b := w.Add(Corpus{Mass: 2, Tag: "b"})
+	c := w.Add(Corpus{Mass: 3, Tag: "c"})
+
+	if !w.Remove(b) || w.Remove(b) || len(w.Corpi) != 2 {
+		t.Error("WRONG !!")
+	}
+
+	if ca, ok := w.Get(a); !ok || ca.Tag != "a" {
+		t.Error("WRONG !!")
+	}
+
+	if cc, ok := w.Get(c); !ok || cc.Tag != "c" {
+		t.Error("WRONG !!")
+	}
+
+	if _, ok := w.Get(b); ok {
+		t.Error("WRONG !!")
+	}
+
+	// IDs are never reused.
+	if d := w.Add(Corpus{Mass: 4}); d == a || d == b || d == c {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_MergeOnContact(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.MergeOnContact = true
+	a := w.Add(Corpus{Pos: Vector{0, 0}, Vel: Vector{1, 0}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{2.5, 0}, Vel: Vector{-1, 0}, Mass: 3, Radius: 1})
+	w.Add(Corpus{Pos: Vector{3.9, 0}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{1, 1}, Mass: 1, Radius: 1, Immaterial: true})
+	mass, momentum := 5.0, w.TotalMomentum()
+	w.Step(0.5)
+
+	// The first two touch and the merged corpus reaches the third.
+	if len(w.Corpi) != 2 || w.IDs()[0] != a || !w.Corpi[1].Immaterial {
+		t.Error("WRONG !!")
+	}
+
+	if math.Abs(w.Corpi[0].Mass-mass) > Epsilon || !w.TotalMomentum().Equals(momentum, Epsilon) {
+		t.Error("WRONG !!")
+	}
+}
+
+func TestWorld_MergeOnContact_Cascade(t *testing.T) {
+	w := NewWorld(0, 0)
+	w.MergeOnContact = true
+	a := w.Add(Corpus{Pos: Vector{0, 0}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{2.5, 0}, Mass: 1, Radius: 1})
+	w.Add(Corpus{Pos: Vector{4.5, 0}, Mass: 1, Radius: 3})
+	w.Step(0.1)
+
+	// Only the last two touch, but the corpus they merge into reaches the
+	// first one, before them.
+	if len(w.Corpi) != 1 || w.IDs()[0] != a || math.Abs(w.Corpi[0].Mass-3) > Epsilon {
+		t.Error("WRONG !!")
+	}
+}